@@ -0,0 +1,30 @@
+package main
+
+import (
+	"io"
+	"strings"
+)
+
+// asciiReplacer spells out the symbols used in steps and notes in ASCII.
+// The steps are always built with the pretty symbols; output that has to
+// be plain ASCII is passed through asciiText instead.
+var asciiReplacer = strings.NewReplacer("↔", "<->", "→", "->", "×", "*")
+
+// asciiText returns s with "L1 ↔ L2" written as "L1 <-> L2" and
+// "L2 + -3L1 → L2" as "L2 + -3L1 -> L2".
+func asciiText(s string) string {
+	return asciiReplacer.Replace(s)
+}
+
+// asciiWriter passes everything written to it through asciiText, for the
+// command-line modes started with -ascii.
+type asciiWriter struct {
+	w io.Writer
+}
+
+func (a asciiWriter) Write(p []byte) (int, error) {
+	if _, err := asciiReplacer.WriteString(a.w, string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestASCIIText(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"L1 ↔ L2", "L1 <-> L2"},
+		{"L2 + -3L1 → L2", "L2 + -3L1 -> L2"},
+		{"Equations 1 and 3 are dependent: equation 3 is 2 × equation 1", "Equations 1 and 3 are dependent: equation 3 is 2 * equation 1"},
+		{"x = 1, y = 2", "x = 1, y = 2"},
+	}
+	for _, tt := range tests {
+		if got := asciiText(tt.in); got != tt.want {
+			t.Errorf("asciiText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRunCLIASCII(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := runCLI([]string{"x + 2y = 4", "3x + y = 7"}, headlessOptions{decimals: 2}, asciiWriter{&stdout}, &stderr); code != 0 {
+		t.Fatalf("exit code %d, stderr %q", code, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "->") || !strings.Contains(out, "<->") {
+		t.Errorf("output has no ASCII arrows:\n%s", out)
+	}
+	for _, r := range out {
+		if r > 127 {
+			t.Fatalf("output contains %q:\n%s", r, out)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/saedarm/go-gaussian/solver"
+)
+
+// batchResult is the outcome of solving one system of a batch run.
+type batchResult struct {
+	input     string   // where the system came from, e.g. "problems.txt #3"
+	equations []string // the system as read from the file
+	kind      solver.Kind
+	solution  string // the values of the unknowns for a unique solution
+	err       error  // set when the system could not be parsed
+	anomaly   string // why a -debug elimination halted, see solver.Matrix.Anomaly
+	steps     string // the steps and result, as the command line prints them
+}
+
+func (r batchResult) verdict() string {
+	if r.err != nil {
+		return "parse error: " + r.err.Error()
+	}
+	if r.anomaly != "" {
+		return "halted: " + r.anomaly
+	}
+	if r.solution != "" {
+		return r.kind.String() + ": " + r.solution
+	}
+	return r.kind.String()
+}
+
+// readBatchFile reads the systems of a batch file: one equation per line,
+// with a blank line between systems. Lines starting with '#' are comments.
+func readBatchFile(path string) ([][]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var systems [][]string
+	var system []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "#"):
+		case line == "":
+			if len(system) > 0 {
+				systems = append(systems, system)
+				system = nil
+			}
+		default:
+			system = append(system, line)
+		}
+	}
+	if len(system) > 0 {
+		systems = append(systems, system)
+	}
+	return systems, nil
+}
+
+// solveBatch solves every system with the headless solver. Systems that
+// don't parse are recorded with their error and the rest are still solved;
+// with debug, so are systems whose elimination halts.
+func solveBatch(name string, systems [][]string, opts headlessOptions) []batchResult {
+	results := make([]batchResult, len(systems))
+	for i, equations := range systems {
+		r := &results[i]
+		r.input = fmt.Sprintf("%s #%d", name, i+1)
+		r.equations = equations
+		m, errs := solver.Parse(equations)
+		for _, err := range errs {
+			if err != nil {
+				r.err = err
+				break
+			}
+		}
+		if r.err != nil {
+			continue
+		}
+		var steps strings.Builder
+		printSolve(m, equations, opts, &steps, &steps)
+		r.steps = steps.String()
+		if r.anomaly = m.Anomaly(); r.anomaly != "" {
+			continue
+		}
+		r.kind = m.Classify()
+		if r.kind == solver.Unique {
+			r.solution = m.SolutionString()
+		}
+	}
+	return results
+}
+
+// runBatch solves the systems in the file at path, prints the verdict of
+// each and the summary, and, unless dir is empty, writes them with every
+// system's steps to a report in dir, in ASCII with opts.ascii and with the
+// matrix after every step with opts.verbose. It returns the process exit
+// code: 1 if the file can't be read or any system fails to parse or halts.
+func runBatch(path, dir string, opts headlessOptions, stdout, stderr io.Writer) int {
+	systems, err := readBatchFile(path)
+	if err != nil {
+		fmt.Fprintln(stderr, "Could not read "+path+": "+err.Error())
+		return 1
+	}
+	results := solveBatch(filepath.Base(path), systems, opts)
+	code := 0
+	for _, r := range results {
+		fmt.Fprintf(stdout, "%s\t%s\n", r.input, r.verdict())
+		if r.err != nil || r.anomaly != "" {
+			code = 1
+		}
+	}
+	fmt.Fprintln(stdout, summarizeBatch(results))
+
+	if dir == "" {
+		return code
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintln(stderr, "Could not write the report: "+err.Error())
+		return 1
+	}
+	report := filepath.Join(dir, "gaussian_batch_"+time.Now().Format("2006-01-02_15-04-05")+".txt")
+	if err := writeBatchReport(report, results, opts.ascii); err != nil {
+		fmt.Fprintln(stderr, "Could not write the report: "+err.Error())
+		return 1
+	}
+	fmt.Fprintln(stdout, "Report written to "+report)
+	return code
+}
+
+// summarizeBatch produces the one-line overview printed when a batch
+// finishes, e.g. "Solved 18 systems: 15 unique, 2 infinite, 1 none; 3 parse
+// errors". Systems a -debug run halted on are counted on their own.
+func summarizeBatch(results []batchResult) string {
+	var unique, infinite, none, failed, halted int
+	for _, r := range results {
+		switch {
+		case r.err != nil:
+			failed++
+		case r.anomaly != "":
+			halted++
+		case r.kind == solver.Infinite:
+			infinite++
+		case r.kind == solver.None:
+			none++
+		default:
+			unique++
+		}
+	}
+
+	solved := unique + infinite + none
+	plural := "s"
+	if solved == 1 {
+		plural = ""
+	}
+	summary := fmt.Sprintf("Solved %d system%s: %d unique, %d infinite, %d none", solved, plural, unique, infinite, none)
+	if failed == 1 {
+		summary += "; 1 parse error"
+	} else if failed > 1 {
+		summary += fmt.Sprintf("; %d parse errors", failed)
+	}
+	if halted > 0 {
+		summary += fmt.Sprintf("; %d halted", halted)
+	}
+	return summary
+}
+
+// writeBatchReport lists every input of a batch with its verdict, one per
+// line, followed by the summary and then, for each system, its equations
+// and its steps and result or parse error. With ascii the arrows are
+// spelled out, see asciiText.
+func writeBatchReport(path string, results []batchResult, ascii bool) error {
+	var b strings.Builder
+	for _, r := range results {
+		b.WriteString(fmt.Sprintf("%s\t%s\n", r.input, r.verdict()))
+	}
+	b.WriteString("\n" + summarizeBatch(results) + "\n")
+	for _, r := range results {
+		b.WriteString("\n== " + r.input + " ==\n")
+		b.WriteString(strings.Join(r.equations, "\n") + "\n\n")
+		if r.err != nil {
+			b.WriteString("Parse error: " + r.err.Error() + "\n")
+		} else {
+			b.WriteString(r.steps)
+		}
+	}
+	report := b.String()
+	if ascii {
+		report = asciiText(report)
+	}
+	return os.WriteFile(path, []byte(report), 0644)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saedarm/go-gaussian/solver"
+)
+
+func TestSummarizeBatch(t *testing.T) {
+	results := []batchResult{
+		{input: "a", kind: solver.Unique},
+		{input: "b", kind: solver.Unique},
+		{input: "c", kind: solver.Infinite},
+		{input: "d", kind: solver.None},
+		{input: "e", err: errors.New("bad")},
+	}
+	if got, want := summarizeBatch(results), "Solved 4 systems: 2 unique, 1 infinite, 1 none; 1 parse error"; got != want {
+		t.Errorf("summarizeBatch = %q, want %q", got, want)
+	}
+	if got, want := summarizeBatch(results[:1]), "Solved 1 system: 1 unique, 0 infinite, 0 none"; got != want {
+		t.Errorf("summarizeBatch = %q, want %q", got, want)
+	}
+}
+
+func TestWriteBatchReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.txt")
+	results := []batchResult{
+		{input: "problems.txt #1", equations: []string{"x = 1"}, kind: solver.Unique, steps: "Initial matrix\n"},
+		{input: "problems.txt #2", equations: []string{"x = 1", "x = q"}, err: &solver.ParseError{Equation: 1, Msg: "invalid constant on right side", Text: "q", Pos: 4}},
+	}
+	if err := writeBatchReport(path, results, false); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"problems.txt #1\tunique\n",
+		"problems.txt #2\tparse error: equation 2: invalid constant on right side",
+		"Solved 1 system: 1 unique, 0 infinite, 0 none; 1 parse error\n",
+		"\n== problems.txt #1 ==\nx = 1\n\nInitial matrix\n",
+		"\n== problems.txt #2 ==\nx = 1\nx = q\n\nParse error: equation 2: invalid constant on right side",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("report missing %q:\n%s", want, b)
+		}
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "problems.txt")
+	content := `# week 3
+x + y = 3
+x - y = 1
+
+2x + y = 1
+x + e = 2
+# dependent
+x + y = 1
+2x + 2y = 2
+
+
+x + y = 1
+x + y = 2
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	systems, err := readBatchFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(systems) != 3 || len(systems[1]) != 4 {
+		t.Fatalf("systems = %q, want 3 with the comment not splitting the second", systems)
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Replace(content, "x + e = 2\n", "x + e = 2\n\n", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr strings.Builder
+	out := filepath.Join(dir, "out")
+	if code := runBatch(path, out, headlessOptions{decimals: 2}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit code = %d, want 1 for the parse error", code)
+	}
+	for _, want := range []string{
+		"problems.txt #1\tunique: x = 2, y = 1\n",
+		"problems.txt #2\tparse error: equation 2: ",
+		"problems.txt #3\tinfinite\n",
+		"problems.txt #4\tnone\n",
+		"Solved 3 systems: 1 unique, 1 infinite, 1 none; 1 parse error",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, stdout.String())
+		}
+	}
+	files, err := filepath.Glob(filepath.Join(out, "gaussian_batch_*.txt"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("report files = %v (%v), want one", files, err)
+	}
+	report, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"problems.txt #1\tunique: x = 2, y = 1\n",
+		"\n== problems.txt #1 ==\nx + y = 3\nx - y = 1\n\n",
+		"L2 → -0.50L2\n",
+		"\nSolution:\nx = 2, y = 1\n",
+		"\n== problems.txt #2 ==\n2x + y = 1\nx + e = 2\n\nParse error: equation 2: ",
+		"\n== problems.txt #3 ==\nx + y = 1\n2x + 2y = 2\n\n",
+		"Infinitely many solutions\n",
+		"No solution (inconsistent system)\n",
+	} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	if code := runBatch(filepath.Join(dir, "missing.txt"), "", headlessOptions{decimals: 2}, &stdout, &stderr); code != 1 || stderr.Len() == 0 {
+		t.Errorf("missing file: exit code %d, stderr %q", code, stderr.String())
+	}
+}
+
+func TestRunBatchToleranceAndDebug(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "problems.txt")
+	if err := os.WriteFile(path, []byte("x + y = 2\n1e-11y = 1e-11\n\nx + y = 1\n1e-13y = 1e-13\n\nx + y = 1\n2x + 2y = 2\n\nx - y = 0\n1e-13y = 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr strings.Builder
+	if code := runBatch(path, "", headlessOptions{decimals: 2}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "problems.txt #1\tinfinite\n") {
+		t.Errorf("default epsilon: exit code %d, output:\n%s", code, stdout.String())
+	}
+
+	stdout.Reset()
+	dumps := t.TempDir()
+	code := runBatch(path, "", headlessOptions{debug: true, decimals: 2, tol: solver.Tolerance{Epsilon: 1e-12}, dumpDir: dumps}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1 for the halted system", code)
+	}
+	for _, want := range []string{
+		"problems.txt #1\tunique: x = 1, y = 1\n",
+		"problems.txt #2\thalted: pivot lost to tolerance in column 2",
+		"problems.txt #3\tinfinite\n",
+		"problems.txt #4\thalted: pivot lost to tolerance in column 2",
+		"Solved 2 systems: 1 unique, 1 infinite, 0 none; 2 halted",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("epsilon 1e-12 with -debug: output missing %q:\n%s", want, stdout.String())
+		}
+	}
+	if files, _ := filepath.Glob(filepath.Join(dumps, "gaussian_debug_*.txt")); len(files) != 2 {
+		t.Errorf("debug dumps = %v, want one for each halted system", files)
+	}
+}
+
+func TestRunBatchReportInASCIIWithMatrices(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "problems.txt")
+	if err := os.WriteFile(path, []byte("x + y = 3\nx - y = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr strings.Builder
+	out := filepath.Join(dir, "out")
+	if code := runBatch(path, out, headlessOptions{decimals: 2, verbose: true, ascii: true}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	files, err := filepath.Glob(filepath.Join(out, "gaussian_batch_*.txt"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("report files = %v (%v), want one", files, err)
+	}
+	report, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(string(report), "↔→") {
+		t.Errorf("report has Unicode arrows under -ascii:\n%s", report)
+	}
+	if want := "L2 -> -0.50L2\n[1 1 | 3]\n[0 1 | 1]\n"; !strings.Contains(string(report), want) {
+		t.Errorf("report missing %q:\n%s", want, report)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/saedarm/go-gaussian/solver"
+)
+
+// MatrixFromCSV reads an augmented matrix from a CSV file: one row per
+// equation, the coefficients followed by the constant. Entries may be
+// decimals or fractions like 1/3, and lines starting with '#' are skipped.
+// A "|" in the same place in every row, such as "1,0,|,1,2" or "1,0|1,2",
+// ends the coefficients, so the columns after it are several right-hand
+// sides; see solver.ParseAugmentedRows.
+func MatrixFromCSV(path string) (*solver.Matrix, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = -1 // checked below, with a clearer message
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, len(records))
+	for i, record := range records {
+		lines[i] = strings.Join(record, ",")
+	}
+	return solver.ParseAugmentedRows(lines)
+}
+
+// runCSV solves the matrix in a CSV file given with -csv and prints the
+// steps and the result like runCLI.
+func runCSV(path string, opts headlessOptions, stdout, stderr io.Writer) int {
+	m, err := MatrixFromCSV(path)
+	if err != nil {
+		fmt.Fprintln(stderr, "Could not read "+path+": "+err.Error())
+		return 1
+	}
+	opts.verbose = false // as in runCLI, the printed steps aren't a saved report
+	return printSolve(m, nil, opts, stdout, stderr)
+}
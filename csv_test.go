@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeCSV(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "system.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMatrixFromCSV(t *testing.T) {
+	m, err := MatrixFromCSV(writeCSV(t, "# x, y, z, constant\n2, 1, -1, 8\n-3,-1,2,-11\n-2, 1, 2, -3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]float64{{2, 1, -1, 8}, {-3, -1, 2, -11}, {-2, 1, 2, -3}}
+	if m.Rows() != 3 || m.Cols() != 4 || !reflect.DeepEqual(m.Data(), want) {
+		t.Errorf("matrix = %dx%d %v, want %v", m.Rows(), m.Cols(), m.Data(), want)
+	}
+
+	half, err := MatrixFromCSV(writeCSV(t, "1/2,1\n"))
+	if err != nil || half.At(0, 0) != 0.5 {
+		t.Errorf("fraction entry: %v, %v", half, err)
+	}
+
+	two, err := MatrixFromCSV(writeCSV(t, "1,0,|,1,2\n0,2,|,4,6\n"))
+	if err != nil || two.CoefficientColumns() != 2 || two.GetMatrixString() != "[1 0 | 1 2]\n[0 2 | 4 6]\n" {
+		t.Errorf("two right-hand sides: %v, %v", two, err)
+	}
+
+	for content, want := range map[string]string{
+		"1,2,3\n4,5\n":   "row 2 has 2 entries, expected 3",
+		"1,a,3\n":        `row 1, column 2: invalid number "a"`,
+		"5\n":            "at least one row with two entries",
+		"1,|,2\n1,2,|\n": "row 2 puts the '|' in a different column than row 1",
+		"":               "at least one row with two entries",
+	} {
+		if _, err := MatrixFromCSV(writeCSV(t, content)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("MatrixFromCSV(%q) error = %v, want %q", content, err, want)
+		}
+	}
+}
+
+func TestRunCSV(t *testing.T) {
+	var stdout, stderr strings.Builder
+	if code := runCSV(writeCSV(t, "1,1,3\n1,-1,1\n"), headlessOptions{decimals: 2}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d, stderr %q", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "x = 2, y = 1") {
+		t.Errorf("output has no solution:\n%s", stdout.String())
+	}
+	stdout.Reset()
+	if code := runCSV(writeCSV(t, "1,0|1,2\n0,2|4,6\n"), headlessOptions{decimals: 2}, &stdout, &stderr); code != 0 {
+		t.Fatalf("two right-hand sides: exit code = %d, stderr %q", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "b1: x = 1, y = 2\nb2: x = 2, y = 3\n") {
+		t.Errorf("output has no solution per right-hand side:\n%s", stdout.String())
+	}
+	if code := runCSV(filepath.Join(t.TempDir(), "missing.csv"), headlessOptions{decimals: 2}, &stdout, &stderr); code != 1 {
+		t.Errorf("missing file: exit code = %d, want 1", code)
+	}
+}
@@ -1,4 +1,4 @@
-module ebitgauss
+module github.com/saedarm/go-gaussian
 
 go 1.24.0
 
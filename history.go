@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// historyFile keeps the systems solved so far, one per line with the
+// equations separated by "; ", oldest first. At most historyLimit are kept.
+const (
+	historyFile  = "history.txt"
+	historyLimit = 50
+)
+
+// loadHistory reads the history file at path. A missing file is an empty
+// history.
+func loadHistory(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var history []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			history = append(history, line)
+		}
+	}
+	return history, nil
+}
+
+// appendHistory adds system to history unless it repeats the last entry,
+// drops the oldest entries beyond limit and rewrites the file at path.
+func appendHistory(path string, history []string, system string, limit int) ([]string, error) {
+	if len(history) > 0 && history[len(history)-1] == system {
+		return history, nil
+	}
+	history = append(history, system)
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return history, err
+	}
+	return history, os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0644)
+}
+
+// loadHistory reads the saved history into g for recall with Up/Down.
+func (g *Game) loadHistory() {
+	history, err := loadHistory(filepath.Join(g.solutionsDir(), historyFile))
+	if err != nil {
+		log.Printf("Error reading history: %v", err)
+	}
+	g.history = history
+	g.historyIndex = len(history)
+}
+
+// recordHistory saves the equations of the current solve to the history.
+func (g *Game) recordHistory() {
+	history, err := appendHistory(filepath.Join(g.solutionsDir(), historyFile), g.history, strings.Join(g.originalEquations, "; "), historyLimit)
+	if err != nil {
+		log.Printf("Error saving history: %v", err)
+	}
+	g.history = history
+	g.historyIndex = len(history)
+}
+
+// recallHistory moves delta entries through the history, -1 being one
+// system further back, and fills the fields with that system.
+func (g *Game) recallHistory(delta int) {
+	i := g.historyIndex + delta
+	if i < 0 || i >= len(g.history) {
+		return
+	}
+	if err := g.setEquations(splitSystem(g.history[i])); err != nil {
+		g.errorMsg = "Could not recall the system: " + err.Error()
+		return
+	}
+	g.historyIndex = i
+	g.errorMsg = ""
+	g.errorField = -1
+}
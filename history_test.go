@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAppendHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "solutions", historyFile)
+	if h, err := loadHistory(path); err != nil || h != nil {
+		t.Fatalf("missing file: history %q, err %v", h, err)
+	}
+
+	var history []string
+	var err error
+	for _, system := range []string{"x = 1", "x + y = 2; x - y = 0", "x + y = 2; x - y = 0", "2x = 4", "3x = 9"} {
+		if history, err = appendHistory(path, history, system, 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"x + y = 2; x - y = 0", "2x = 4", "3x = 9"}
+	if !reflect.DeepEqual(history, want) {
+		t.Errorf("history = %q, want %q", history, want)
+	}
+	if got, err := loadHistory(path); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("reloaded history = %q, %v; want %q", got, err, want)
+	}
+}
+
+func TestRecallHistory(t *testing.T) {
+	t.Chdir(t.TempDir())
+	g := &Game{equations: []string{"2x + y = 3", "x - y = 0"}, errorField: -1}
+	g.solve()
+	g.reset()
+	g.equations = []string{"x = 5"}
+	g.solve()
+	if _, err := os.Stat(filepath.Join(outputDir, historyFile)); err != nil {
+		t.Fatalf("history not saved: %v", err)
+	}
+
+	g.reset()
+	g.loadHistory()
+	g.recallHistory(-1)
+	if !reflect.DeepEqual(g.equations, []string{"x = 5"}) {
+		t.Errorf("Up recalled %q, want the last system", g.equations)
+	}
+	g.recallHistory(-1)
+	g.recallHistory(-1)
+	if !reflect.DeepEqual(g.equations, []string{"2x + y = 3", "x - y = 0"}) {
+		t.Errorf("Up twice recalled %q, want the first system", g.equations)
+	}
+	g.recallHistory(1)
+	if !reflect.DeepEqual(g.equations, []string{"x = 5"}) {
+		t.Errorf("Down recalled %q", g.equations)
+	}
+}
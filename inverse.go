@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/saedarm/go-gaussian/solver"
+)
+
+// showInverse reads the inverse off g.matrix, an eliminated [A | I], and
+// lists its rows after the steps.
+func (g *Game) showInverse() {
+	inv, err := g.matrix.InverseBlock()
+	if err != nil {
+		g.solutionKind = solver.None
+		g.solution = "The matrix is singular and has no inverse"
+		return
+	}
+	g.inverse = inv
+	g.solutionKind = solver.Unique
+	g.steps = append(g.steps, "\nInverse:")
+	g.steps = append(g.steps, strings.Split(strings.TrimSuffix(inv.GetPlainMatrixString(), "\n"), "\n")...)
+	g.solution = "Inverse found (the right half of the final matrix)"
+}
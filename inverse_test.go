@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/saedarm/go-gaussian/solver"
+)
+
+func TestSolveInInvertMode(t *testing.T) {
+	g := &Game{equations: []string{"2x + y = 0", "x + y = 0"}, errorField: -1, reopening: true, invert: true}
+	g.solve()
+	if g.inverse == nil || g.solutionKind != solver.Unique {
+		t.Fatalf("no inverse found: %q", g.solution)
+	}
+	if got := g.steps[len(g.steps)-2:]; got[0] != "[1 -1]" || got[1] != "[-1 2]" {
+		t.Errorf("inverse rows shown as %q", got)
+	}
+
+	g.equations = []string{"x + 2y = 0", "2x + 4y = 0"}
+	g.solve()
+	if g.inverse != nil || g.solutionKind != solver.None {
+		t.Errorf("singular matrix: inverse %v, kind %v", g.inverse, g.solutionKind)
+	}
+}
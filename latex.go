@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/saedarm/go-gaussian/solver"
+)
+
+// ExportLaTeX writes m and the elimination steps as a standalone LaTeX
+// document. Row operations are typeset as math with subscripted labels,
+// e.g. "L2 + -0.50L1 → L2" becomes $L_{2} + -0.50L_{1} \rightarrow L_{2}$;
+// other steps are kept as text.
+func ExportLaTeX(m *solver.Matrix, steps []string) string {
+	var b strings.Builder
+	b.WriteString("\\documentclass{article}\n")
+	b.WriteString("\\usepackage{amsmath}\n")
+	b.WriteString("\\begin{document}\n\n")
+
+	b.WriteString("\\[\n")
+	b.WriteString(latexMatrix(m))
+	b.WriteString("\\]\n\n")
+
+	labels := rowLabelRegex(m.Labels().Prefix)
+	b.WriteString("\\begin{enumerate}\n")
+	for _, step := range steps {
+		step = strings.TrimSpace(step)
+		if step == "" || step == "Solution:" {
+			continue
+		}
+		b.WriteString("  \\item " + latexStep(step, labels) + "\n")
+	}
+	b.WriteString("\\end{enumerate}\n\n")
+
+	b.WriteString("\\end{document}\n")
+	return b.String()
+}
+
+// latexMatrix renders m as a bracketed array, one row per line, with a
+// bar before the right-hand side as in GetMatrixString.
+func latexMatrix(m *solver.Matrix) string {
+	var b strings.Builder
+	columns := strings.Repeat("c", m.Cols())
+	if n := m.CoefficientColumns(); n < m.Cols() {
+		columns = columns[:n] + "|" + columns[n:]
+	}
+	b.WriteString("\\left[\\begin{array}{" + columns + "}\n")
+	for i := 0; i < m.Rows(); i++ {
+		cells := make([]string, m.Cols())
+		for j := range cells {
+			cells[j] = solver.FormatNumber(m.At(i, j), m.Decimals())
+		}
+		b.WriteString(strings.Join(cells, " & "))
+		if i < m.Rows()-1 {
+			b.WriteString(" \\\\")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\\end{array}\\right]\n")
+	return b.String()
+}
+
+// rowLabelRegex matches the row labels with the given prefix in step
+// descriptions (L1, R0, ...).
+func rowLabelRegex(prefix string) *regexp.Regexp {
+	return regexp.MustCompile("(" + regexp.QuoteMeta(prefix) + `)(\d+)`)
+}
+
+// latexStep typesets a row operation as math, subscripting the row labels
+// matched by labels, and escapes any other step as text.
+func latexStep(step string, labels *regexp.Regexp) string {
+	if !strings.ContainsAny(step, "→↔") {
+		return latexEscape(step)
+	}
+	s := labels.ReplaceAllString(step, "${1}_{${2}}")
+	s = strings.ReplaceAll(s, "→", `\rightarrow`)
+	s = strings.ReplaceAll(s, "↔", `\leftrightarrow`)
+	return "$" + s + "$"
+}
+
+// latexEscape escapes the characters LaTeX treats specially in text.
+func latexEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\textbackslash{}`, "&", `\&`, "%", `\%`, "$", `\$`,
+		"#", `\#`, "_", `\_`, "{", `\{`, "}", `\}`, "~", `\textasciitilde{}`, "^", `\textasciicircum{}`)
+	return r.Replace(s)
+}
+
+// exportLaTeX writes the last solve as LaTeX to the output directory.
+func (g *Game) exportLaTeX() {
+	if g.original == nil {
+		g.errorMsg = "Solve a system before exporting it as LaTeX"
+		return
+	}
+	dir := g.solutionsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		g.saveStatus = "Could not export LaTeX: " + err.Error()
+		return
+	}
+	name := fmt.Sprintf("%s%s.tex", solutionFilePrefix, time.Now().Format("2006-01-02_15-04-05"))
+	path := filepath.Join(dir, name)
+	// The system as parsed keeps the precision, the row labels and, when
+	// inverting, the bar before the identity.
+	if err := os.WriteFile(path, []byte(ExportLaTeX(g.original, g.steps)), 0644); err != nil {
+		g.saveStatus = "Could not export LaTeX: " + err.Error()
+		return
+	}
+	g.saveStatus = "LaTeX written to " + path
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saedarm/go-gaussian/solver"
+)
+
+func TestExportLaTeX(t *testing.T) {
+	m, errs := solver.Parse([]string{"x + 2y = 3", "-4x + 5y = 6"})
+	if m == nil {
+		t.Fatal(errs)
+	}
+	steps := []string{"Starting Gaussian Elimination...", "L2 ↔ L1", "L2 + -0.50L1 → L2", "\nSolution:"}
+	got := ExportLaTeX(m, steps)
+
+	for _, want := range []string{
+		"\\documentclass{article}",
+		"\\left[\\begin{array}{cc|c}\n1 & 2 & 3 \\\\\n-4 & 5 & 6\n\\end{array}\\right]",
+		"\\item Starting Gaussian Elimination...",
+		"\\item $L_{2} \\leftrightarrow L_{1}$",
+		"\\item $L_{2} + -0.50L_{1} \\rightarrow L_{2}$",
+		"\\end{document}",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Solution:") {
+		t.Errorf("output contains the display heading:\n%s", got)
+	}
+}
+
+func TestLatexStepEscapesText(t *testing.T) {
+	if got, want := latexStep("50% of x_1 & y", rowLabelRegex("L")), `50\% of x\_1 \& y`; got != want {
+		t.Errorf("latexStep = %q, want %q", got, want)
+	}
+}
+
+func TestExportLaTeXUsesThePrecision(t *testing.T) {
+	dir := t.TempDir()
+	g := &Game{equations: []string{"2x + y = 0.25", "x - y = 1"}, errorField: -1, reopening: true, decimals: 3, noSave: true, saveDir: dir}
+	g.solve()
+	g.exportLaTeX()
+	files, err := filepath.Glob(filepath.Join(dir, "*.tex"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("LaTeX files = %v (%v), status %q", files, err, g.saveStatus)
+	}
+	b, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "2 & 1 & 0.250 \\\\\n1 & -1 & 1\n") {
+		t.Errorf("matrix not written with 3 decimals:\n%s", b)
+	}
+}
+
+func TestExportLaTeXWithZeroIndexedLabels(t *testing.T) {
+	m, errs := solver.Parse([]string{"x + 2y = 3", "-4x + 5y = 6"})
+	if m == nil {
+		t.Fatal(errs)
+	}
+	m.SetRowLabels("R", 0)
+	got := ExportLaTeX(m, m.GaussianElimination())
+	if want := "\\item $R_{1} \\leftrightarrow R_{0}$"; !strings.Contains(got, want) {
+		t.Errorf("output does not contain %q:\n%s", want, got)
+	}
+
+	m.SetRowLabels("Row", 1)
+	if got := latexStep("Row2 + 4Row1 → Row2", rowLabelRegex(m.Labels().Prefix)); got != `$Row_{2} + 4Row_{1} \rightarrow Row_{2}$` {
+		t.Errorf("latexStep with prefix Row = %q", got)
+	}
+}
+
+func TestExportLaTeXOfAnInverse(t *testing.T) {
+	dir := t.TempDir()
+	g := &Game{equations: []string{"2x + y = 0", "x - y = 0"}, errorField: -1, reopening: true, invert: true, noSave: true, saveDir: dir}
+	g.solve()
+	g.exportLaTeX()
+	files, err := filepath.Glob(filepath.Join(dir, "*.tex"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("LaTeX files = %v (%v), status %q", files, err, g.saveStatus)
+	}
+	b, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "\\begin{array}{cc|cc}\n2 & 1 & 1 & 0 \\\\\n") {
+		t.Errorf("the bar is not before the identity:\n%s", b)
+	}
+}
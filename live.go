@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/saedarm/go-gaussian/solver"
+)
+
+// liveDelay is how many ticks the equations must stay unchanged before
+// live mode solves them, so it doesn't run on every keystroke.
+const liveDelay = 20
+
+// livePreview solves the equations with the headless solver, with the
+// decimals, tolerance and exact mode a solve would use, and describes the
+// result in one line, or returns "" if any equation doesn't parse yet.
+func (g *Game) livePreview() string {
+	m, _ := solver.Parse(g.equations)
+	if m == nil {
+		return ""
+	}
+	m.SetDecimals(g.decimals)
+	m.SetTolerance(g.tol)
+	var exact *solver.RatMatrix
+	if g.exact && !g.invert && g.method == methodGaussJordan {
+		exact = solver.ExactMatrix(m)
+	}
+	homogeneous := m.IsHomogeneous()
+	m.Solve()
+	kind := m.Classify()
+	if exact != nil {
+		exact.GaussianElimination()
+		kind = exact.Classify()
+	}
+	switch kind {
+	case solver.None:
+		return "No solution (inconsistent system)"
+	case solver.Infinite:
+		return "Infinitely many solutions: " + m.GeneralSolution()
+	}
+	switch {
+	case homogeneous:
+		return m.TrivialSolution()
+	case exact != nil:
+		return exact.SolutionString()
+	}
+	return m.SolutionString()
+}
+
+// updateLive re-solves the system for the live preview once the equations
+// have stopped changing for liveDelay ticks.
+func (g *Game) updateLive() {
+	if !g.live || g.solving {
+		g.preview = ""
+		g.liveInput = ""
+		return
+	}
+	input := strings.Join(g.equations, "\n")
+	if input != g.liveInput {
+		g.liveInput = input
+		g.liveTicks = liveDelay
+		g.preview = ""
+		return
+	}
+	if g.liveTicks > 0 {
+		g.liveTicks--
+		if g.liveTicks == 0 {
+			g.preview = g.livePreview()
+		}
+	}
+}
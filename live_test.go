@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/saedarm/go-gaussian/solver"
+)
+
+func TestLivePreview(t *testing.T) {
+	tests := []struct {
+		equations []string
+		want      string
+	}{
+		{[]string{"x + y = 3", "x - y = 1"}, "x = 2, y = 1"},
+		{[]string{"x + y = 3", "x - "}, ""},
+		{[]string{"x + y = 3", ""}, ""},
+		{[]string{"x + y = 1", "x + y = 2"}, "No solution (inconsistent system)"},
+		{[]string{"x + y = 1", "2x + 2y = 2"}, "Infinitely many solutions: x = 1 - y, y free"},
+	}
+	for _, tt := range tests {
+		g := &Game{equations: tt.equations, decimals: solver.DefaultDecimals}
+		if got := g.livePreview(); got != tt.want {
+			t.Errorf("livePreview(%q) = %q, want %q", tt.equations, got, tt.want)
+		}
+	}
+}
+
+func TestLivePreviewMatchesTheSolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		game      *Game
+		equations []string
+	}{
+		{"decimals", &Game{decimals: 4}, []string{"3x = 1", "y = 0.123456"}},
+		{"exact", &Game{decimals: solver.DefaultDecimals, exact: true}, []string{"3x = 1", "y = 0.123456"}},
+		{"epsilon", &Game{decimals: solver.DefaultDecimals, tol: solver.Tolerance{Epsilon: 1e-12}}, []string{"x + y = 2", "1e-11y = 1e-11"}},
+	}
+	for _, tt := range tests {
+		g := tt.game
+		g.equations = tt.equations
+		g.errorField = -1
+		g.reopening = true
+		preview := g.livePreview()
+		g.solve()
+		if preview != g.solution {
+			t.Errorf("%s: preview %q, solve shows %q", tt.name, preview, g.solution)
+		}
+	}
+}
+
+func TestUpdateLiveDebounces(t *testing.T) {
+	g := &Game{equations: []string{"x + y = 3", "x - y = 1"}, live: true}
+	for range liveDelay {
+		g.updateLive()
+		if g.preview != "" {
+			t.Fatal("preview shown before the equations settled")
+		}
+	}
+	g.updateLive()
+	if g.preview != "x = 2, y = 1" {
+		t.Errorf("preview = %q after the delay", g.preview)
+	}
+
+	g.equations[1] = "x - y = "
+	g.updateLive()
+	if g.preview != "" {
+		t.Errorf("preview %q kept after an edit", g.preview)
+	}
+	for range liveDelay + 1 {
+		g.updateLive()
+	}
+	if g.preview != "" || g.errorMsg != "" {
+		t.Errorf("unparseable input showed preview %q, error %q", g.preview, g.errorMsg)
+	}
+
+	g.live = false
+	g.updateLive()
+	if g.preview != "" {
+		t.Error("preview kept after turning live mode off")
+	}
+}
@@ -1,21 +1,30 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"image"
 	"image/color"
+	"io"
 	"log"
 	"math"
+	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
-	"regexp"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/saedarm/go-gaussian/solver"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/gofont/goregular"
 	"golang.org/x/image/font/opentype"
@@ -25,11 +34,26 @@ const (
 	screenWidth  = 800
 	screenHeight = 600
 	fontSize     = 20
+	bigFontSize  = 28      // font size in high-contrast mode
 	displayTime  = 60 * 30 // 30 seconds at 60 FPS
 	minWidth     = 800
 	minHeight    = 600
+	idleTPS      = 15 // tick rate while waiting for input in energy-saving mode
+	systemEnvVar = "GAUSSIAN_SYSTEM"
+	outputDir    = "solutions"
+	maxScale     = 4 // largest UI scale factor, for wide windows on high-DPI screens
 )
 
+// outputDirEnvVar overrides outputDir, as does the -output-dir flag.
+const outputDirEnvVar = "GAUSSIAN_OUTPUT_DIR"
+
+// inBrowser is true in the WebAssembly build, where there is no file system
+// to save solutions to, so -no-save is on by default.
+const inBrowser = runtime.GOOS == "js"
+
+// themeFile, in the output directory, holds the theme picked with F10.
+const themeFile = "theme.txt"
+
 type Button struct {
 	x, y, w, h int
 	text       string
@@ -39,12 +63,191 @@ func (b *Button) Contains(x, y int) bool {
 	return x >= b.x && x < b.x+b.w && y >= b.y && y < b.y+b.h
 }
 
-type Matrix struct {
-	rows int
-	cols int
-	data [][]float64
+// Theme holds the colors used by Draw.
+type Theme struct {
+	Background  color.RGBA
+	Text        color.RGBA
+	Muted       color.RGBA
+	Field       color.RGBA
+	ActiveField color.RGBA
+	ErrorField  color.RGBA
+	Border      color.RGBA
+	BorderWidth float64 // outline drawn around fields and step boxes, 0 for none
+	Highlight   color.RGBA
+	Step        color.RGBA
+	Error       color.RGBA
+	Button      color.RGBA
+	ButtonText  color.RGBA
+	// Action button colors when idle, hovered and pressed.
+	Action [3]color.RGBA
+	// Banner background and text colors per solution type.
+	Unique, Infinite, None [2]color.RGBA
+}
+
+var lightTheme = Theme{
+	Background:  color.RGBA{240, 240, 240, 255},
+	Text:        color.RGBA{0, 0, 0, 255},
+	Muted:       color.RGBA{100, 100, 100, 255},
+	Field:       color.RGBA{255, 255, 255, 255},
+	ActiveField: color.RGBA{200, 200, 255, 255},
+	ErrorField:  color.RGBA{255, 230, 230, 255},
+	Border:      color.RGBA{220, 0, 0, 255},
+	Highlight:   color.RGBA{255, 220, 90, 255},
+	Step:        color.RGBA{255, 255, 255, 255},
+	Error:       color.RGBA{255, 0, 0, 255},
+	Button:      color.RGBA{200, 50, 50, 255},
+	ButtonText:  color.RGBA{255, 255, 255, 255},
+	Action:      [3]color.RGBA{{50, 110, 200, 255}, {80, 140, 230, 255}, {30, 70, 150, 255}},
+	Unique:      [2]color.RGBA{{230, 255, 230, 255}, {0, 100, 0, 255}},
+	Infinite:    [2]color.RGBA{{225, 235, 255, 255}, {0, 60, 160, 255}},
+	None:        [2]color.RGBA{{255, 225, 225, 255}, {170, 0, 0, 255}},
+}
+
+// darkTheme is light gray text on a dark background, chosen with F10.
+var darkTheme = Theme{
+	Background:  color.RGBA{30, 30, 34, 255},
+	Text:        color.RGBA{225, 225, 225, 255},
+	Muted:       color.RGBA{150, 150, 160, 255},
+	Field:       color.RGBA{50, 50, 56, 255},
+	ActiveField: color.RGBA{55, 60, 110, 255},
+	ErrorField:  color.RGBA{90, 35, 35, 255},
+	Border:      color.RGBA{230, 80, 80, 255},
+	Highlight:   color.RGBA{120, 95, 20, 255},
+	Step:        color.RGBA{45, 45, 50, 255},
+	Error:       color.RGBA{255, 110, 110, 255},
+	Button:      color.RGBA{170, 50, 50, 255},
+	ButtonText:  color.RGBA{255, 255, 255, 255},
+	Action:      [3]color.RGBA{{50, 100, 180, 255}, {70, 125, 210, 255}, {30, 65, 130, 255}},
+	Unique:      [2]color.RGBA{{25, 60, 30, 255}, {140, 230, 140, 255}},
+	Infinite:    [2]color.RGBA{{25, 40, 75, 255}, {140, 180, 255, 255}},
+	None:        [2]color.RGBA{{75, 25, 25, 255}, {255, 140, 140, 255}},
+}
+
+// highContrastTheme is white and yellow on black with thick outlines.
+var highContrastTheme = Theme{
+	Background:  color.RGBA{0, 0, 0, 255},
+	Text:        color.RGBA{255, 255, 255, 255},
+	Muted:       color.RGBA{255, 255, 0, 255},
+	Field:       color.RGBA{0, 0, 0, 255},
+	ActiveField: color.RGBA{0, 0, 150, 255},
+	ErrorField:  color.RGBA{120, 0, 0, 255},
+	Border:      color.RGBA{255, 255, 255, 255},
+	BorderWidth: 3,
+	Highlight:   color.RGBA{170, 0, 170, 255},
+	Step:        color.RGBA{0, 0, 0, 255},
+	Error:       color.RGBA{255, 90, 90, 255},
+	Button:      color.RGBA{255, 255, 0, 255},
+	ButtonText:  color.RGBA{0, 0, 0, 255},
+	Action:      [3]color.RGBA{{255, 255, 0, 255}, {255, 255, 170, 255}, {190, 190, 0, 255}},
+	Unique:      [2]color.RGBA{{0, 0, 0, 255}, {0, 255, 0, 255}},
+	Infinite:    [2]color.RGBA{{0, 0, 0, 255}, {0, 255, 255, 255}},
+	None:        [2]color.RGBA{{0, 0, 0, 255}, {255, 90, 90, 255}},
+}
+
+// banner returns the banner background and text colors for a verdict.
+func (t *Theme) banner(kind solver.Kind) (bg, fg color.RGBA) {
+	switch kind {
+	case solver.Infinite:
+		return t.Infinite[0], t.Infinite[1]
+	case solver.None:
+		return t.None[0], t.None[1]
+	}
+	return t.Unique[0], t.Unique[1]
+}
+
+// screenLayout holds the positions and sizes Draw lays things out with.
+type screenLayout struct {
+	headerY      [3]int // baselines of the title and the two instruction lines
+	margin       int    // left edge of the fields and steps
+	textX        int    // left edge of the text in them
+	fieldTop     int
+	fieldWidth   int
+	fieldHeight  int
+	fieldSpacing int
+	textInset    int // distance from the top of a box to the text baseline
+	errorGap     int // from the bottom of the last field to the error baseline
+	stepsGap     int // from the bottom of the last field to the first step
+	stepSpacing  int
+	stepHeight   int
+	cellWidth    int
+}
+
+var (
+	normalLayout = screenLayout{
+		headerY: [3]int{40, 70, 90}, margin: 20, textX: 30, fieldTop: 100,
+		fieldWidth: 400, fieldHeight: 40, fieldSpacing: 60, textInset: 30,
+		errorGap: 40, stepsGap: 60, stepSpacing: 45, stepHeight: 35, cellWidth: 90,
+	}
+	// largeLayout has bigger boxes and spacing to fit largeFont text and
+	// make the fields easier to hit.
+	largeLayout = screenLayout{
+		headerY: [3]int{44, 84, 118}, margin: 20, textX: 30, fieldTop: 132,
+		fieldWidth: 560, fieldHeight: 56, fieldSpacing: 72, textInset: 40,
+		errorGap: 48, stepsGap: 88, stepSpacing: 58, stepHeight: 48, cellWidth: 110,
+	}
+)
+
+// scaled returns l with every position and size multiplied by s.
+func (l screenLayout) scaled(s float64) screenLayout {
+	px := func(n int) int { return int(math.Round(float64(n) * s)) }
+	for i := range l.headerY {
+		l.headerY[i] = px(l.headerY[i])
+	}
+	l.margin, l.textX = px(l.margin), px(l.textX)
+	l.fieldTop, l.fieldWidth, l.fieldHeight = px(l.fieldTop), px(l.fieldWidth), px(l.fieldHeight)
+	l.fieldSpacing, l.textInset = px(l.fieldSpacing), px(l.textInset)
+	l.errorGap, l.stepsGap = px(l.errorGap), px(l.stepsGap)
+	l.stepSpacing, l.stepHeight, l.cellWidth = px(l.stepSpacing), px(l.stepHeight), px(l.cellWidth)
+	return l
+}
+
+// fieldY returns the top of equation field i.
+func (l screenLayout) fieldY(i int) int {
+	return l.fieldTop + i*l.fieldSpacing
+}
+
+// errorY returns the error message baseline below the given number of
+// fields.
+func (l screenLayout) errorY(fields int) int {
+	return l.fieldY(fields-1) + l.fieldHeight + l.errorGap
+}
+
+// stepsTop returns the baseline of the first solution step below the given
+// number of fields.
+func (l screenLayout) stepsTop(fields int) int {
+	return l.fieldY(fields-1) + l.fieldHeight + l.stepsGap
+}
+
+// solveMethod is how solve reduces the system, chosen with F9.
+type solveMethod int
+
+const (
+	methodGaussJordan      solveMethod = iota // reduce to reduced row echelon form
+	methodBackSubstitution                    // reduce to row echelon form, then substitute upwards
+	methodCramer                              // ratios of determinants, for square systems
+	methodJacobi                              // fixed-point iteration on the previous iterate
+	methodGaussSeidel                         // fixed-point iteration using new values at once
+	methodCount
+)
+
+func (s solveMethod) String() string {
+	switch s {
+	case methodBackSubstitution:
+		return "Back substitution"
+	case methodCramer:
+		return "Cramer's rule"
+	case methodJacobi:
+		return "Jacobi"
+	case methodGaussSeidel:
+		return "Gauss-Seidel"
+	}
+	return "Gauss-Jordan"
 }
 
+// maxFields is the number of equation fields that fit in the window. The
+// headless modes take systems of any size.
+const maxFields = 6
+
 type Game struct {
 	equations           []string
 	activeEquation      int
@@ -55,7 +258,7 @@ type Game struct {
 	currentStep         int
 	stepDelay           int
 	font                font.Face
-	matrix              *Matrix
+	matrix              *solver.Matrix
 	width, height       int
 	errorMsg            string
 	isRunning           bool
@@ -64,177 +267,253 @@ type Game struct {
 	ShowExitPrompt      bool
 	solutionDisplayDone bool
 	closeButton         Button
+	energySaving        bool
+	redraw              bool
+	solutionKind        solver.Kind
+	errorField          int
+	parseError          *solver.ParseError
+	freeVariables       int
+	verdict             string // the kind of system, shown in a banner above the solution
+	methodFailed        bool   // Cramer's rule or the iteration found no answer
+	generalSolution     string
+	parametricSolution  string
+	determinant         string
+	rank                string
+	warning             string
+	report              string
+	saveStatus          string
+	reopening           bool
+	reopenIndex         int
+	metricsPath         string
+	rowLabels           solver.RowLabels
+	debug               bool
+	decimals            int
+	highContrast        bool
+	faces               map[float64]font.Face
+	exact               bool              // eliminate in rational arithmetic
+	invert              bool              // invert the coefficient matrix instead of solving
+	inverse             *solver.Matrix    // the inverse found by the last solve in invert mode
+	showLU              bool              // list the LU factorization after the steps
+	exactMatrix         *solver.RatMatrix // the exact elimination of the last solve
+	scroll              int               // how far the steps are scrolled up, in pixels
+	paused              bool              // the animation only moves with Left/Right
+	stepSpeed           int               // 0 (manual) to len(stepDelays)
+	coefficients        [][]float64
+	method              solveMethod
+	history             []string // solved systems, oldest first
+	historyIndex        int      // entry shown by Up/Down, len(history) when none
+	saveDir             string   // where solutions are written, outputDir if empty
+	noSave              bool     // don't write reports or history on solve
+	dark                bool     // use darkTheme, kept in themeFile
+	scale               float64  // UI scale set by Layout, 1 when zero
+	residuals           []float64
+	original            *solver.Matrix   // the last system solved, before elimination
+	originalEquations   []string         // the fields original was parsed from
+	undo                map[int][]string // earlier text of each field, newest last
+	cursors             map[int]int      // caret in each field, at the end if missing
+	caretTicks          int              // ticks since the caret moved, for blinking
+	live                bool             // solve while typing, toggled with F11
+	liveInput           string           // the equations the preview is for
+	liveTicks           int              // ticks left before the preview is solved
+	preview             string           // live result, empty while the input doesn't parse
+	nullSpace           [][]float64      // basis of Ax = 0 for a dependent homogeneous system
+	dependencies        []string         // empty equations and pairs that are multiples of each other
+	matrixMode          bool             // the fields are a grid of matrix cells
+	cells               [][]string       // matrix-mode cells, one row per equation
+	cellRow, cellCol    int              // the active cell
+	ascii               bool             // saved reports use ASCII arrows
+	verbose             bool             // saved reports show the matrix after every step
+	tol                 solver.Tolerance // zero threshold and rounding of the elimination
+	performance         string           // row operation counts and timing of the elimination
 }
 
-func NewMatrix(rows, cols int) *Matrix {
-	data := make([][]float64, rows)
-	for i := range data {
-		data[i] = make([]float64, cols)
-	}
-	return &Matrix{
-		rows: rows,
-		cols: cols,
-		data: data,
+// maxDecimals is the largest precision F6 cycles through.
+const maxDecimals = 6
+
+// stepDelays are the ticks between animation steps at speeds 1 to 5. At
+// speed 0 the animation is manual and only moves with the arrow keys.
+var stepDelays = []int{60, 45, 30, 15, 5}
+
+const defaultStepSpeed = 3
+
+// splitSystem splits a whole system written as one string into its
+// equations. Equations may be separated by semicolons or newlines.
+func splitSystem(system string) []string {
+	fields := strings.FieldsFunc(system, func(r rune) bool {
+		return r == ';' || r == '\n' || r == '\r'
+	})
+	equations := []string{}
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			equations = append(equations, f)
+		}
 	}
+	return equations
 }
 
-// Matrix operations
-func (m *Matrix) SwapRows(row1, row2 int) {
-	m.data[row1], m.data[row2] = m.data[row2], m.data[row1]
+// readClipboard returns the text on the system clipboard, using the
+// platform's own clipboard tool.
+func readClipboard() (string, error) {
+	var cmds [][]string
+	switch runtime.GOOS {
+	case "windows":
+		cmds = [][]string{{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"}}
+	case "darwin":
+		cmds = [][]string{{"pbpaste"}}
+	default:
+		cmds = [][]string{{"wl-paste", "--no-newline"}, {"xclip", "-selection", "clipboard", "-o"}, {"xsel", "--clipboard", "--output"}}
+	}
+	err := errors.New("no clipboard tool found")
+	for _, c := range cmds {
+		out, cmdErr := exec.Command(c[0], c[1:]...).Output()
+		if cmdErr == nil {
+			return string(out), nil
+		}
+		err = cmdErr
+	}
+	return "", err
 }
 
-func (m *Matrix) MultiplyRow(row int, scalar float64) {
-	for j := 0; j < m.cols; j++ {
-		m.data[row][j] *= scalar
+// writeClipboard puts text on the system clipboard, using the platform's
+// own clipboard tool.
+func writeClipboard(text string) error {
+	var cmds [][]string
+	switch runtime.GOOS {
+	case "windows":
+		cmds = [][]string{{"clip"}}
+	case "darwin":
+		cmds = [][]string{{"pbcopy"}}
+	default:
+		cmds = [][]string{{"wl-copy"}, {"xclip", "-selection", "clipboard", "-i"}, {"xsel", "--clipboard", "--input"}}
 	}
+	err := errors.New("no clipboard tool found")
+	for _, c := range cmds {
+		cmd := exec.Command(c[0], c[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err = cmd.Run(); err == nil {
+			return nil
+		}
+	}
+	return err
 }
 
-func (m *Matrix) AddMultipleOfRow(targetRow, sourceRow int, scalar float64) {
-	for j := 0; j < m.cols; j++ {
-		m.data[targetRow][j] += scalar * m.data[sourceRow][j]
+// copyText returns what Ctrl+C copies: the solution line, or with full the
+// whole report as saved to the solutions file.
+func (g *Game) copyText(full bool) (string, error) {
+	if g.solution == "" {
+		return "", errors.New("solve a system before copying the solution")
 	}
+	if full {
+		return g.report, nil
+	}
+	return g.solution, nil
 }
 
-func (m *Matrix) GetMatrixString() string {
-	var result strings.Builder
-	for i := 0; i < m.rows; i++ {
-		result.WriteString(fmt.Sprintf("[%.2f %.2f %.2f | %.2f]\n",
-			m.data[i][0], m.data[i][1], m.data[i][2], m.data[i][3]))
+// copySolution copies the solution, or with full the report, to the
+// clipboard and confirms it under the solution.
+func (g *Game) copySolution(full bool) {
+	text, err := g.copyText(full)
+	if err != nil {
+		g.errorMsg = "Could not copy: " + err.Error()
+		return
+	}
+	if err := writeClipboard(text); err != nil {
+		g.saveStatus = "Could not copy to the clipboard: " + err.Error()
+		return
+	}
+	if full {
+		g.saveStatus = "Copied the full report to the clipboard"
+	} else {
+		g.saveStatus = "Copied the solution to the clipboard"
 	}
-	return result.String()
 }
 
-func (m *Matrix) GaussianElimination() []string {
-	steps := []string{}
-	lead := 0
-
-	isZero := func(x float64) bool {
-		return math.Abs(x) < 1e-10
+// pasteSystem fills the equation fields from pasted text, one equation per
+// line (or separated by ';'), adding or removing fields to fit. A single
+// equation goes into the active field.
+func (g *Game) pasteSystem(pasted string) {
+	equations := splitSystem(pasted)
+	switch len(equations) {
+	case 0:
+		g.errorMsg = "The clipboard has no equations to paste"
+		return
+	case 1:
+		g.typeText(equations[0])
+		return
 	}
-
-	round := func(x float64, precision int) float64 {
-		multiplier := math.Pow(10, float64(precision))
-		return math.Round(x*multiplier) / multiplier
+	if err := g.setEquations(equations); err != nil {
+		g.errorMsg = "Could not paste: " + err.Error()
+		return
 	}
+	g.errorMsg = ""
+	g.errorField = -1
+}
 
-	steps = append(steps, "Starting Gaussian Elimination...")
-
-	for r := 0; r < m.rows; r++ {
-		if lead >= m.cols {
-			return steps
-		}
-
-		i := r
-		for isZero(m.data[i][lead]) {
-			i++
-			if i == m.rows {
-				i = r
-				lead++
-				if lead == m.cols {
-					return steps
-				}
-			}
-		}
-
-		if i != r {
-			m.SwapRows(i, r)
-			steps = append(steps, fmt.Sprintf("L%d ↔ L%d", i+1, r+1))
-		}
-
-		if !isZero(m.data[r][lead] - 1) {
-			scalar := 1.0 / m.data[r][lead]
-			scalar = round(scalar, 5)
-			m.MultiplyRow(r, scalar)
-			steps = append(steps, fmt.Sprintf("L%d → %.2fL%d", r+1, scalar, r+1))
-		}
-
-		for i := 0; i < m.rows; i++ {
-			if i != r {
-				scalar := -m.data[i][lead]
-				if !isZero(scalar) {
-					scalar = round(scalar, 5)
-					m.AddMultipleOfRow(i, r, scalar)
-					if scalar == -1 {
-						steps = append(steps, fmt.Sprintf("L%d + L%d → L%d", i+1, r+1, i+1))
-					} else {
-						steps = append(steps, fmt.Sprintf("L%d + %.2fL%d → L%d", i+1, scalar, r+1, i+1))
-					}
-				}
-			}
-		}
-
-		for i := 0; i < m.rows; i++ {
-			for j := 0; j < m.cols; j++ {
-				m.data[i][j] = round(m.data[i][j], 5)
-			}
+// readSystemFile reads a system with one equation per line. Blank lines and
+// lines starting with '#' are skipped; lines[i] is the 1-based line number
+// of equations[i].
+func readSystemFile(path string) (equations []string, lines []int, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
 		}
-
-		lead++
+		equations = append(equations, line)
+		lines = append(lines, i+1)
 	}
-
-	return steps
+	return equations, lines, nil
 }
-func parseEquation(eq string) ([]float64, error) {
-	eq = strings.ToLower(strings.ReplaceAll(eq, " ", ""))
-	coeffs := make([]float64, 4)
-
-	parts := strings.Split(eq, "=")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("equation must contain exactly one '=' sign")
-	}
-
-	leftSide := parts[0]
-	rightSide := parts[1]
 
-	constant, err := strconv.ParseFloat(rightSide, 64)
+// loadSystemFromFile fills the equation fields from a file given with
+// -file. Problems are shown as the error message, pointing at the first
+// line that doesn't parse.
+func (g *Game) loadSystemFromFile(path string) {
+	equations, lines, err := readSystemFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("invalid constant on right side")
+		g.errorMsg = "Could not read " + path + ": " + err.Error()
+		return
 	}
-	coeffs[3] = constant
-
-	termRegex := regexp.MustCompile(`[+-]?\d*\.?\d*[xyz]|[+-]?\d+\.?\d*`)
-	terms := termRegex.FindAllString(leftSide, -1)
-
-	for _, term := range terms {
-		coeff := 1.0
-		var variable rune
-
-		if len(term) > 0 {
-			if term[0] == '-' {
-				coeff = -1.0
-				term = term[1:]
-			} else if term[0] == '+' {
-				term = term[1:]
-			}
-
-			if len(term) > 0 {
-				if term[0] >= '0' && term[0] <= '9' || term[0] == '.' {
-					numPart := term[:len(term)-1]
-					if val, err := strconv.ParseFloat(numPart, 64); err == nil {
-						coeff *= val
-					}
-				}
-			}
-
-			if len(term) > 0 {
-				variable = rune(term[len(term)-1])
-				switch variable {
-				case 'x':
-					coeffs[0] += coeff
-				case 'y':
-					coeffs[1] += coeff
-				case 'z':
-					coeffs[2] += coeff
-				}
-			}
+	if err := g.setEquations(equations); err != nil {
+		g.errorMsg = fmt.Sprintf("%s: %v", filepath.Base(path), err)
+		return
+	}
+	for i, eq := range equations {
+		if _, err := solver.ParseEquation(eq); err != nil {
+			g.errorMsg = fmt.Sprintf("%s line %d: %v", filepath.Base(path), lines[i], err)
+			g.errorField = i
+			g.activeEquation = i
+			errors.As(err, &g.parseError)
+			return
 		}
 	}
+}
 
-	return coeffs, nil
+// setEquations replaces the equation fields, one field per equation.
+func (g *Game) setEquations(equations []string) error {
+	if len(equations) == 0 {
+		return errors.New("a system needs at least one equation")
+	}
+	if len(equations) > maxFields {
+		return fmt.Errorf("%d equations given, at most %d are supported", len(equations), maxFields)
+	}
+	g.equations = append([]string(nil), equations...)
+	g.undo = nil
+	g.cursors = nil
+	if g.matrixMode {
+		g.cells = cellsFromEquations(g.equations)
+	}
+	if g.activeEquation >= len(g.equations) {
+		g.activeEquation = len(g.equations) - 1
+	}
+	return nil
 }
 
 func (g *Game) getContentHeight() int {
-	contentHeight := minHeight // Start with minimum height
+	contentHeight := g.px(minHeight) // Start with minimum height
 
 	if g.solving || g.solutionComplete {
 		numVisibleSteps := g.currentStep + 1
@@ -242,31 +521,65 @@ func (g *Game) getContentHeight() int {
 			numVisibleSteps = len(g.steps)
 		}
 
-		height := 320 + (numVisibleSteps * 45)
+		l := g.layout()
+		height := l.stepsTop(len(g.equations)) + g.gridHeight() + (numVisibleSteps * l.stepSpacing)
 
 		if g.solution != "" {
-			height += 80
+			height += g.px(80)
+		}
+		if g.verdict != "" {
+			height += l.stepSpacing
+		}
+		if g.rank != "" {
+			height += l.stepSpacing
+		}
+		if g.warning != "" {
+			height += l.stepSpacing
+		}
+		height += len(g.dependencies) * l.stepSpacing
+		if g.residuals != nil {
+			height += l.stepSpacing
+		}
+		if g.performance != "" {
+			height += l.stepSpacing
+		}
+		if g.freeVariables > 0 {
+			height += 3 * l.stepSpacing
+		}
+		if g.nullSpace != nil {
+			height += l.stepSpacing
 		}
 
-		height += 100
+		height += g.px(100)
 
 		if height > contentHeight {
 			contentHeight = height
 		}
 	}
 
-	if contentHeight < minHeight {
-		contentHeight = minHeight
-	}
-
 	return contentHeight
 }
 
+// Layout matches the screen to the window in device pixels, so larger
+// windows and high-DPI screens get larger, sharp text and wide windows get
+// wider step boxes. The screen is never narrower than minWidth at the UI
+// scale; Ebiten shrinks it into smaller windows. Whatever doesn't fit below
+// is reached by scrolling, and the limit shrinks again when the solution
+// is cleared.
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	contentHeight := g.getContentHeight()
-
-	width := minWidth
-	height := contentHeight
+	deviceScale := 1.0
+	if m := ebiten.Monitor(); m != nil {
+		deviceScale = m.DeviceScaleFactor()
+	}
+	scale := uiScale(outsideWidth, outsideHeight, deviceScale)
+	rescaled := scale != g.uiScale()
+	g.scale = scale
+
+	width := max(int(math.Round(float64(outsideWidth)*deviceScale)), g.px(minWidth))
+	height := g.px(minHeight)
+	if outsideWidth > 0 {
+		height = outsideHeight * width / outsideWidth
+	}
 
 	if width < 1 {
 		width = 1
@@ -275,24 +588,152 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeigh
 		height = 1
 	}
 
+	if width != g.width || height != g.height {
+		g.redraw = true
+	}
+	resized := width != g.width
 	g.width = width
 	g.height = height
+	if rescaled || resized {
+		g.applyScale()
+	}
+	g.scrollBy(0)
 
 	return width, height
 }
 
+// uiScale returns the UI scale for a window of the given size in
+// device-independent pixels: the number of device pixels per pixel of the
+// 800×600 layout that still fits the window both ways, in steps of a
+// quarter so the font isn't reloaded on every resize. Smaller windows keep
+// a scale of 1 and are shrunk by Ebiten.
+func uiScale(outsideWidth, outsideHeight int, deviceScale float64) float64 {
+	fit := min(float64(outsideWidth)/minWidth, float64(outsideHeight)/minHeight)
+	s := math.Floor(fit*deviceScale*4) / 4
+	return min(max(s, 1), maxScale)
+}
+
+func (g *Game) uiScale() float64 {
+	if g.scale == 0 {
+		return 1
+	}
+	return g.scale
+}
+
+// px converts a length in the unscaled layout to screen pixels.
+func (g *Game) px(n int) int {
+	return int(math.Round(float64(n) * g.uiScale()))
+}
+
+// applyScale loads the font for the current scale and mode and resizes the
+// close button to match.
+func (g *Game) applyScale() {
+	size, w, h := float64(fontSize), 100, 40
+	if g.highContrast {
+		size, w, h = bigFontSize, 130, 52
+	}
+	if face, err := g.fontFace(size * g.uiScale()); err == nil {
+		g.font = face
+	}
+	g.closeButton.w, g.closeButton.h = g.px(w), g.px(h)
+	g.closeButton.x = g.screenWidth() - g.px(20) - g.closeButton.w
+	g.closeButton.y = g.px(20)
+}
+
+// screenWidth returns the width of the screen set by Layout.
+func (g *Game) screenWidth() int {
+	if g.width > 0 {
+		return g.width
+	}
+	return g.px(minWidth)
+}
+
+// fontFace returns the font at the given size, loading it the first time.
+func (g *Game) fontFace(size float64) (font.Face, error) {
+	if face, ok := g.faces[size]; ok {
+		return face, nil
+	}
+	face, err := loadFont(size)
+	if err != nil {
+		return nil, err
+	}
+	if g.faces == nil {
+		g.faces = make(map[float64]font.Face)
+	}
+	g.faces[size] = face
+	return face, nil
+}
+
+// maxScroll is how far the steps can be scrolled before the end of the
+// content reaches the bottom of the screen.
+func (g *Game) maxScroll() int {
+	if m := g.getContentHeight() - g.height; m > 0 {
+		return m
+	}
+	return 0
+}
+
+// scrollBy moves the steps by dy pixels, clamped to the content.
+func (g *Game) scrollBy(dy int) {
+	g.scroll += dy
+	if limit := g.maxScroll(); g.scroll > limit {
+		g.scroll = limit
+	}
+	if g.scroll < 0 {
+		g.scroll = 0
+	}
+}
+
+// handleScroll scrolls the steps with the mouse wheel and the arrow and
+// page keys.
+func (g *Game) handleScroll() {
+	const (
+		wheelStep = 40
+		keyStep   = 8
+	)
+	_, dy := ebiten.Wheel()
+	delta := int(-dy * float64(g.px(wheelStep)))
+	if ebiten.IsKeyPressed(ebiten.KeyDown) {
+		delta += g.px(keyStep)
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyUp) {
+		delta -= g.px(keyStep)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPageDown) {
+		delta += g.height / 2
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPageUp) {
+		delta -= g.height / 2
+	}
+	if delta != 0 {
+		g.scrollBy(delta)
+	}
+}
+
 func (g *Game) Update() error {
 	if !g.isRunning {
 		return nil
 	}
 
-	// Handle mouse clicks for close button
+	// Handle mouse clicks for the buttons and equation fields
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
 		x, y := ebiten.CursorPosition()
 		if g.closeButton.Contains(x, y) {
 			g.isRunning = false
 			return ebiten.Termination
 		}
+		if b := g.solveButton(); b.Contains(x, y) {
+			g.solve()
+		}
+		if b := g.clearButton(); b.Contains(x, y) {
+			g.reset()
+		}
+		if r, c := g.cellAt(x, y); g.matrixMode && r >= 0 {
+			g.cellRow, g.cellCol = r, c
+		} else if i := g.fieldAt(x, y); i >= 0 && !g.matrixMode {
+			g.activeEquation = i
+			g.setCursor(g.cursorAt(g.equations[i], x-g.layout().textX))
+		}
 	}
 
 	// Handle window closing event
@@ -312,7 +753,13 @@ func (g *Game) Update() error {
 		return ebiten.Termination
 	}
 
+	g.updateTickRate()
+	g.redraw = true
+	g.caretTicks++
+
 	g.handleInput()
+	g.handleScroll()
+	g.updateLive()
 
 	// Handle solution timer
 	if g.keepWindowOpen {
@@ -326,248 +773,1735 @@ func (g *Game) Update() error {
 	}
 
 	// Continue animation even after solution is complete
-	if g.solving && g.currentStep < len(g.steps) {
+	if g.solving && !g.paused && g.stepSpeed > 0 && g.currentStep < len(g.steps) {
 		g.stepDelay++
-		if g.stepDelay > 30 {
-			g.currentStep++
-			g.stepDelay = 0
-			if g.currentStep >= len(g.steps) {
-				g.solutionComplete = true
-			}
+		if g.stepDelay > stepDelays[g.stepSpeed-1] {
+			g.stepForward()
 		}
 	}
 
 	return nil
 }
 
-func (g *Game) handleInput() {
-	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) {
-		if len(g.equations[g.activeEquation]) > 0 {
-			g.equations[g.activeEquation] = g.equations[g.activeEquation][:len(g.equations[g.activeEquation])-1]
-		}
+// stepForward shows the next step of the animation.
+func (g *Game) stepForward() {
+	if !g.solving || g.currentStep >= len(g.steps) {
 		return
 	}
-
-	if inpututil.IsKeyJustPressed(ebiten.KeyTab) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-		g.activeEquation = (g.activeEquation + 1) % 3
-		return
+	g.currentStep++
+	g.stepDelay = 0
+	if g.currentStep >= len(g.steps) {
+		g.solutionComplete = true
 	}
+	// Follow the animation so the newest step stays in view.
+	g.scrollBy(g.maxScroll())
+}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		g.solve()
+// stepBack hides the last step shown, going back to the matrix before it.
+func (g *Game) stepBack() {
+	if !g.solving {
 		return
 	}
-
-	for k := ebiten.Key0; k <= ebiten.Key9; k++ {
-		if inpututil.IsKeyJustPressed(k) {
-			g.equations[g.activeEquation] += strconv.Itoa(int(k - ebiten.Key0))
-		}
-	}
-
-	if inpututil.IsKeyJustPressed(ebiten.KeyX) {
-		g.equations[g.activeEquation] += "x"
-	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyY) {
-		g.equations[g.activeEquation] += "y"
+	// Once the animation has finished currentStep is one past the last
+	// step, which is shown all the same.
+	if g.currentStep >= len(g.steps) {
+		g.currentStep = len(g.steps) - 1
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
-		g.equations[g.activeEquation] += "z"
+	if g.currentStep > 0 {
+		g.currentStep--
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) {
-		g.equations[g.activeEquation] += "-"
+	g.stepDelay = 0
+	g.solutionComplete = false
+	g.scrollBy(0)
+}
+
+// skipToSolution shows every remaining step and the answer at once. The
+// answer then stays up for the full displayTime, as at the end of an
+// animation.
+func (g *Game) skipToSolution() {
+	if !g.solving {
+		return
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) {
-		g.equations[g.activeEquation] += "="
+	g.currentStep = len(g.steps)
+	g.stepDelay = 0
+	g.paused = false
+	g.solutionComplete = true
+	g.solutionTimer = displayTime
+	g.keepWindowOpen = true
+	g.solutionDisplayDone = false
+	g.scrollBy(g.maxScroll())
+}
+
+// updateTickRate drops to idleTPS when energy saving is on and nothing is
+// animating or counting down, and returns to the default rate otherwise.
+func (g *Game) updateTickRate() {
+	tps := ebiten.DefaultTPS
+	animating := g.solving && g.currentStep < len(g.steps)
+	if g.energySaving && !animating && !g.keepWindowOpen {
+		tps = idleTPS
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeySlash) {
-		g.equations[g.activeEquation] += "+"
+	if ebiten.TPS() != tps {
+		ebiten.SetTPS(tps)
 	}
 }
-func (g *Game) Draw(screen *ebiten.Image) {
-	// Get actual screen dimensions
-	actualWidth, actualHeight := screen.Size()
 
-	// Fill background
-	screen.Fill(color.RGBA{240, 240, 240, 255})
+func (g *Game) handleInput() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF2) {
+		g.energySaving = !g.energySaving
+		return
+	}
 
-	// Draw title and instructions
-	text.Draw(screen, "Gaussian Elimination Solver", g.font, 20, 40, color.Black)
-	text.Draw(screen, "Enter equations in the form: 2x + y - z = 8", g.font, 20, 70, color.RGBA{100, 100, 100, 255})
-	text.Draw(screen, "Press SPACE to solve | ESC to exit", g.font, 20, 90, color.RGBA{100, 100, 100, 255})
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		g.toggleHighContrast()
+		return
+	}
 
-	// Draw close button
-	ebitenutil.DrawRect(screen, float64(g.closeButton.x), float64(g.closeButton.y),
-		float64(g.closeButton.w), float64(g.closeButton.h),
-		color.RGBA{200, 50, 50, 255}) // Red button
-	bound := text.BoundString(g.font, g.closeButton.text)
-	x := g.closeButton.x + (g.closeButton.w-bound.Dx())/2
-	y := g.closeButton.y + (g.closeButton.h+bound.Dy())/2
-	text.Draw(screen, g.closeButton.text, g.font, x, y, color.White)
+	if inpututil.IsKeyJustPressed(ebiten.KeyF11) {
+		g.live = !g.live
+		return
+	}
 
-	// Draw equation input fields
-	for i := 0; i < 3; i++ {
-		y := 100 + i*60
-		ebitenutil.DrawRect(screen, 20, float64(y), 400, 40, color.RGBA{255, 255, 255, 255})
-		if i == g.activeEquation {
-			ebitenutil.DrawRect(screen, 20, float64(y), 400, 40, color.RGBA{200, 200, 255, 255})
-		}
-		text.Draw(screen, g.equations[i], g.font, 30, y+30, color.Black)
+	if inpututil.IsKeyJustPressed(ebiten.KeyF10) {
+		g.toggleDark()
+		return
 	}
 
-	// Draw solution steps
-	if g.solving || g.solutionComplete {
-		y := 320
-		for i := 0; i <= g.currentStep && i < len(g.steps); i++ {
-			ebitenutil.DrawRect(screen, 20, float64(y-25), float64(actualWidth-60), 35, color.RGBA{255, 255, 255, 255})
-			text.Draw(screen, g.steps[i], g.font, 30, y, color.Black)
-			y += 45
+	if inpututil.IsKeyJustPressed(ebiten.KeyF4) {
+		if g.rowLabels == solver.ZeroIndexedLabels {
+			g.rowLabels = solver.OneIndexedLabels
+		} else {
+			g.rowLabels = solver.ZeroIndexedLabels
 		}
+		return
+	}
 
-		if g.solution != "" {
-			ebitenutil.DrawRect(screen, 20, float64(y-25), float64(actualWidth-60), 35, color.RGBA{230, 255, 230, 255})
-			text.Draw(screen, g.solution, g.font, 30, y, color.RGBA{0, 100, 0, 255})
-		}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+		g.exact = !g.exact
+		return
 	}
 
-	// Draw error message if any
-	if g.errorMsg != "" {
-		text.Draw(screen, g.errorMsg, g.font, 20, 300, color.RGBA{255, 0, 0, 255})
+	if inpututil.IsKeyJustPressed(ebiten.KeyF7) {
+		g.invert = !g.invert
+		return
 	}
 
-	// Draw exit prompt if showing
-	if g.ShowExitPrompt {
-		overlayWidth := actualWidth
-		if overlayWidth < 1 {
-			overlayWidth = 1
-		}
-		overlayHeight := actualHeight
-		if overlayHeight < 1 {
-			overlayHeight = 1
-		}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF8) {
+		g.showLU = !g.showLU
+		return
+	}
 
-		overlay := ebiten.NewImage(overlayWidth, overlayHeight)
-		overlay.Fill(color.RGBA{0, 0, 0, 128})
-		opt := &ebiten.DrawImageOptions{}
-		screen.DrawImage(overlay, opt)
+	// F9 switches the method, solving a system that is on screen again so
+	// the methods can be compared.
+	if inpututil.IsKeyJustPressed(ebiten.KeyF9) {
+		g.method = (g.method + 1) % methodCount
+		g.resolve()
+		return
+	}
 
-		msg := "Solution complete. Press ESC to exit."
-		bound := text.BoundString(g.font, msg)
-		x := (overlayWidth - bound.Dx()) / 2
-		y := (overlayHeight - bound.Dy()) / 2
-		text.Draw(screen, msg, g.font, x, y, color.White)
+	if inpututil.IsKeyJustPressed(ebiten.KeyF12) {
+		g.toggleMatrixMode()
+		return
 	}
-}
 
-func (g *Game) solve() {
-	defer func() {
-		if r := recover(); r != nil {
-			log.Printf("Recovered from error in solve: %v", r)
-			g.errorMsg = "An error occurred while solving"
-			g.solving = true
-			g.solutionComplete = true
-		}
-	}()
+	if inpututil.IsKeyJustPressed(ebiten.KeyF6) {
+		g.decimals = (g.decimals + 1) % (maxDecimals + 1)
+		return
+	}
 
-	g.matrix = NewMatrix(3, 4)
-	g.errorMsg = ""
+	if ebiten.IsKeyPressed(ebiten.KeyControl) && inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		g.reopenNext()
+		return
+	}
 
-	for i := 0; i < 3; i++ {
-		if g.equations[i] == "" {
-			g.errorMsg = fmt.Sprintf("Please enter equation %d", i+1)
-			return
-		}
-		coeffs, err := parseEquation(g.equations[i])
+	if (ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)) && inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		clip, err := readClipboard()
 		if err != nil {
-			g.errorMsg = fmt.Sprintf("Error in equation %d: %s", i+1, err)
+			g.errorMsg = "Could not read the clipboard: " + err.Error()
 			return
 		}
-		g.matrix.data[i] = coeffs
+		g.pasteSystem(clip)
+		return
 	}
 
-	g.currentStep = 0
-	g.stepDelay = 0
-	g.solving = true
-	g.solutionComplete = false
-	g.solutionDisplayDone = false
-	g.ShowExitPrompt = false
+	// Ctrl+C copies the solution, Ctrl+Shift+C the whole report.
+	if (ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)) && inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		g.copySolution(ebiten.IsKeyPressed(ebiten.KeyShift))
+		return
+	}
 
-	initialMatrix := g.matrix.GetMatrixString()
-	g.steps = g.matrix.GaussianElimination()
+	// Ctrl with +/- changes the animation speed; without Ctrl they are
+	// typed into the equation.
+	if ebiten.IsKeyPressed(ebiten.KeyControl) && (inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd)) {
+		g.changeSpeed(1)
+		return
+	}
 
-	if math.Abs(g.matrix.data[0][0]) < 1e-10 ||
-		math.Abs(g.matrix.data[1][1]) < 1e-10 ||
-		math.Abs(g.matrix.data[2][2]) < 1e-10 {
-		g.errorMsg = "No unique solution exists"
+	if ebiten.IsKeyPressed(ebiten.KeyControl) && (inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadSubtract)) {
+		g.changeSpeed(-1)
 		return
 	}
 
+	if ebiten.IsKeyPressed(ebiten.KeyControl) && inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		g.exportLaTeX()
+		return
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyControl) && inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		g.exportPNG()
+		return
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyControl) && inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		if err := g.setEquations(append(g.equations, "")); err != nil {
+			g.inputError(-1, err.Error())
+		}
+		return
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyControl) && inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		if err := g.setEquations(g.equations[:len(g.equations)-1]); err != nil {
+			g.inputError(-1, err.Error())
+		}
+		return
+	}
+
+	// Cell edits are not recorded for undo.
+	if ebiten.IsKeyPressed(ebiten.KeyControl) && inpututil.IsKeyJustPressed(ebiten.KeyZ) && !g.matrixMode {
+		g.undoEdit()
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyDelete) {
+		g.reset()
+		return
+	}
+
+	if g.matrixMode && g.handleCellInput() {
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) {
+		if eq, c := g.equations[g.activeEquation], g.cursor(); c > 0 {
+			g.editActive(eq[:c-1] + eq[c:])
+			g.setCursor(c - 1)
+		}
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadEnter) {
+		g.activeEquation = (g.activeEquation + 1) % len(g.equations)
+		return
+	}
+
+	// While a solution is animating, Space pauses it and the arrows step
+	// through it by hand.
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		if g.solving && !g.solutionComplete {
+			g.paused = !g.paused
+		} else {
+			g.solve()
+		}
+		return
+	}
+
+	// Left and Right move the caret while no solution is shown, and step
+	// through the solution otherwise.
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+		if !g.solving {
+			g.setCursor(g.cursor() + 1)
+			return
+		}
+		g.paused = true
+		g.stepForward()
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+		if !g.solving {
+			g.setCursor(g.cursor() - 1)
+			return
+		}
+		g.paused = true
+		g.stepBack()
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyHome) {
+		g.setCursor(0)
+		return
+	}
+
+	// End moves the caret while no solution is shown, and skips the rest
+	// of the animation otherwise.
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnd) {
+		if !g.solving {
+			g.setCursor(len(g.equations[g.activeEquation]))
+			return
+		}
+		g.skipToSolution()
+		return
+	}
+
+	// Up and Down recall earlier systems while no solution is shown, and
+	// scroll the steps otherwise.
+	if !g.solving {
+		if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+			g.recallHistory(-1)
+			return
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+			g.recallHistory(1)
+			return
+		}
+	}
+
+	// The rest is typing, which handleCellInput has done in matrix mode.
+	if g.matrixMode {
+		return
+	}
+
+	for k := ebiten.Key0; k <= ebiten.Key9; k++ {
+		if inpututil.IsKeyJustPressed(k) {
+			// Shift+8, Shift+9 and Shift+0 are "*", "(" and ")" on most
+			// layouts.
+			if s, ok := shiftedDigits[k]; ok && ebiten.IsKeyPressed(ebiten.KeyShift) {
+				g.typeText(s)
+				continue
+			}
+			g.typeText(strconv.Itoa(int(k - ebiten.Key0)))
+		}
+	}
+	for k := ebiten.KeyNumpad0; k <= ebiten.KeyNumpad9; k++ {
+		if inpututil.IsKeyJustPressed(k) {
+			g.typeText(strconv.Itoa(int(k - ebiten.KeyNumpad0)))
+		}
+	}
+
+	// Any letter can be a variable, and "e" is also the exponent in 1.5e-3.
+	// With Control held they are shortcuts instead.
+	shortcut := ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+	for k := ebiten.KeyA; k <= ebiten.KeyZ; k++ {
+		if inpututil.IsKeyJustPressed(k) && !shortcut {
+			g.typeText(string(rune('a' + k - ebiten.KeyA)))
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadSubtract) {
+		g.typeText("-")
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyNumpadEqual) {
+		g.typeText("=")
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.typeText("+")
+		} else {
+			g.typeText("=")
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd) {
+		g.typeText("+")
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeySlash) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadDivide) {
+		g.typeText("/")
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeySemicolon) {
+		g.typeText(";")
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyNumpadMultiply) {
+		g.typeText("*")
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadDecimal) {
+		eq, c := g.equations[g.activeEquation], g.cursor()
+		if s := insertDecimalPoint(eq, c); s != eq {
+			g.editActive(s)
+			g.setCursor(c + 1)
+		}
+	}
+}
+
+// shiftedDigits are the symbols typed with Shift and a digit key.
+var shiftedDigits = map[ebiten.Key]string{
+	ebiten.Key8: "*",
+	ebiten.Key9: "(",
+	ebiten.Key0: ")",
+}
+
+// undoLimit is how many edits Ctrl+Z can undo in each field.
+const undoLimit = 100
+
+// editActive replaces the text of the active field, remembering the old
+// text for undoEdit.
+func (g *Game) editActive(eq string) {
+	i := g.activeEquation
+	if eq == g.equations[i] {
+		return
+	}
+	if g.undo == nil {
+		g.undo = make(map[int][]string)
+	}
+	stack := append(g.undo[i], g.equations[i])
+	if len(stack) > undoLimit {
+		stack = stack[1:]
+	}
+	g.undo[i] = stack
+	g.equations[i] = eq
+}
+
+// typeText inserts s at the caret in the active field.
+func (g *Game) typeText(s string) {
+	eq, c := g.equations[g.activeEquation], g.cursor()
+	g.editActive(eq[:c] + s + eq[c:])
+	g.setCursor(c + len(s))
+}
+
+// cursor returns the caret position in the active field.
+func (g *Game) cursor() int {
+	eq := g.equations[g.activeEquation]
+	if c, ok := g.cursors[g.activeEquation]; ok && c < len(eq) {
+		return c
+	}
+	return len(eq)
+}
+
+// setCursor moves the caret in the active field to c, kept within the
+// text. A caret at the end stays there when the text is replaced.
+func (g *Game) setCursor(c int) {
+	eq := g.equations[g.activeEquation]
+	c = min(max(c, 0), len(eq))
+	if g.cursors == nil {
+		g.cursors = make(map[int]int)
+	}
+	if c == len(eq) {
+		delete(g.cursors, g.activeEquation)
+	} else {
+		g.cursors[g.activeEquation] = c
+	}
+	g.caretTicks = 0
+}
+
+// cursorAt returns the caret position in eq closest to x pixels from the
+// start of the text.
+func (g *Game) cursorAt(eq string, x int) int {
+	if g.font == nil {
+		return len(eq)
+	}
+	best, bestDist := 0, x
+	for i := 1; i <= len(eq); i++ {
+		d := font.MeasureString(g.font, eq[:i]).Ceil() - x
+		if d < 0 {
+			d = -d
+		}
+		if d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// undoEdit restores the active field to its text before the last edit.
+func (g *Game) undoEdit() {
+	i := g.activeEquation
+	stack := g.undo[i]
+	if len(stack) == 0 {
+		return
+	}
+	g.equations[i] = stack[len(stack)-1]
+	g.undo[i] = stack[:len(stack)-1]
+	g.setCursor(len(g.equations[i]))
+}
+
+// insertDecimalPoint adds a "." to eq at pos unless the number around pos
+// already has one.
+func insertDecimalPoint(eq string, pos int) string {
+	inNumber := func(b byte) bool { return b >= '0' && b <= '9' || b == '.' }
+	for i := pos - 1; i >= 0 && inNumber(eq[i]); i-- {
+		if eq[i] == '.' {
+			return eq
+		}
+	}
+	for i := pos; i < len(eq) && inNumber(eq[i]); i++ {
+		if eq[i] == '.' {
+			return eq
+		}
+	}
+	return eq[:pos] + "." + eq[pos:]
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	// The screen is not cleared every frame, so in energy-saving mode the
+	// previous frame can be kept until the next Update changes something.
+	if g.energySaving && !g.redraw {
+		return
+	}
+	g.redraw = false
+
+	// Get actual screen dimensions
+	actualWidth, actualHeight := screen.Bounds().Dx(), screen.Bounds().Dy()
+
+	th := g.theme()
+	l := g.layout()
+
+	// Fill background
+	screen.Fill(th.Background)
+
+	// Draw title and instructions
+	text.Draw(screen, "Gaussian Elimination Solver", g.font, l.margin, l.headerY[0], th.Text)
+	instructions := "Enter equations in the form: 2x + y - z = 8"
+	if g.invert {
+		instructions = "Invert mode: one row per field, e.g. 2x + y - z = 0"
+	}
+	if g.matrixMode {
+		instructions = "Matrix mode: type the coefficients and the constant of each row"
+	}
+	text.Draw(screen, instructions, g.font, l.margin, l.headerY[1], th.Muted)
+	method := "Method: " + g.method.String()
+	text.Draw(screen, method, g.font, actualWidth-l.margin-font.MeasureString(g.font, method).Ceil(), l.headerY[1], th.Muted)
+	hint := "Press SPACE to solve | ESC to exit"
+	if g.solving && !g.solutionComplete {
+		if g.paused {
+			hint = "Paused: LEFT/RIGHT to step | SPACE to resume"
+		} else if g.stepSpeed == 0 {
+			hint = "Manual: LEFT/RIGHT to step | END to skip"
+		} else {
+			hint = "SPACE to pause | END to skip to the solution"
+		}
+	}
+	text.Draw(screen, hint, g.font, l.margin, l.headerY[2], th.Muted)
+	speed := g.speedLabel()
+	speedX := actualWidth - l.margin - font.MeasureString(g.font, speed).Ceil()
+	text.Draw(screen, speed, g.font, speedX, l.headerY[2], th.Muted)
+	if progress := g.progressLabel(); progress != "" {
+		text.Draw(screen, progress, g.font, speedX-g.px(20)-font.MeasureString(g.font, progress).Ceil(), l.headerY[2], th.Muted)
+		// A thin bar under the header fills up as the steps are shown.
+		barY, barWidth := l.headerY[2]+g.px(4), actualWidth-2*l.margin
+		fillRect(screen, l.margin, barY, barWidth, g.px(3), th.Field)
+		fillRect(screen, l.margin, barY, barWidth*g.shownSteps()/len(g.steps), g.px(3), th.Highlight)
+	}
+
+	// Draw close button
+	fillRect(screen, g.closeButton.x, g.closeButton.y, g.closeButton.w, g.closeButton.h, th.Button)
+	g.drawButtonLabel(screen, g.closeButton, th)
+
+	// Draw the solve and clear buttons
+	g.drawActionButton(screen, g.solveButton(), th)
+	g.drawActionButton(screen, g.clearButton(), th)
+
+	// Draw equation input fields
+	for i := range g.equations {
+		y := l.fieldY(i)
+		fill := th.Field
+		if i == g.errorField {
+			b := g.px(2)
+			fillRect(screen, l.margin-b, y-b, l.fieldWidth+2*b, l.fieldHeight+2*b, th.Error)
+			fill = th.ErrorField
+		} else if i == g.activeEquation {
+			fill = th.ActiveField
+		}
+		if g.matrixMode {
+			g.drawCells(screen, i, th)
+			continue
+		}
+		drawBox(screen, l.margin, y, l.fieldWidth, l.fieldHeight, fill, th)
+		if col := g.eliminatedColumn(); col >= 0 {
+			g.highlightVariable(screen, g.equations[i], g.matrix.VariableName(col), l.textX, y)
+		}
+		text.Draw(screen, g.equations[i], g.font, l.textX, y+l.textInset, th.Text)
+		if i == g.activeEquation && g.caretTicks/30%2 == 0 {
+			cx := l.textX + font.MeasureString(g.font, g.equations[i][:g.cursor()]).Ceil()
+			fillRect(screen, cx, y+g.px(8), g.px(2), l.fieldHeight-g.px(16), th.Text)
+		}
+		if i == g.errorField {
+			g.markParseError(screen, g.equations[i], l.textX, y, th)
+		}
+	}
+
+	// Draw solution steps
+	if g.solving || g.solutionComplete {
+		y := l.stepsTop(len(g.equations))
+		top := l.stepHeight - g.px(10) // distance from the box top to the baseline
+		boxWidth := actualWidth - 3*l.margin
+
+		// The steps scroll under the pinned title and fields: they are
+		// drawn onto the part of the screen below the fields only.
+		clip := image.Rect(0, y-top-g.px(5), actualWidth, actualHeight)
+		screen := screen.SubImage(clip).(*ebiten.Image)
+		y -= g.scroll
+		if i := g.traceIndex(); i >= 0 {
+			trace := g.trace()
+			var prev [][]float64
+			if i > 0 && !g.solutionComplete {
+				prev = trace[i-1].After
+			}
+			g.drawMatrixGrid(screen, trace[i], prev, g.matrix.CoefficientColumns(), l.margin, y-top, th)
+			y += g.gridHeight()
+		}
+		for i := 0; i <= g.currentStep && i < len(g.steps); i++ {
+			drawBox(screen, l.margin, y-top, boxWidth, l.stepHeight, th.Step, th)
+			text.Draw(screen, g.steps[i], g.font, l.textX, y, th.Text)
+			y += l.stepSpacing
+		}
+
+		if g.solution != "" {
+			kind := g.solutionKind
+			if g.methodFailed {
+				kind = solver.None
+			}
+			bg, fg := th.banner(kind)
+			if g.verdict != "" {
+				// The verdict swaps the colors of the lines under it so
+				// that it stands out above them.
+				drawBox(screen, l.margin, y-top, boxWidth, l.stepHeight, fg, th)
+				text.Draw(screen, g.verdict, g.font, l.textX, y, bg)
+				y += l.stepSpacing
+			}
+			drawBox(screen, l.margin, y-top, boxWidth, l.stepHeight, bg, th)
+			text.Draw(screen, g.solution, g.font, l.textX, y, fg)
+
+			if g.rank != "" {
+				y += l.stepSpacing
+				drawBox(screen, l.margin, y-top, boxWidth, l.stepHeight, bg, th)
+				text.Draw(screen, g.rankSummary(), g.font, l.textX, y, fg)
+			}
+			if g.warning != "" {
+				y += l.stepSpacing
+				text.Draw(screen, g.warning, g.font, l.textX, y, th.Error)
+			}
+			for _, note := range g.dependencies {
+				y += l.stepSpacing
+				text.Draw(screen, note, g.font, l.textX, y, th.Error)
+			}
+			if g.residuals != nil {
+				y += l.stepSpacing
+				text.Draw(screen, g.residualSummary(), g.font, l.textX, y, th.Muted)
+			}
+			if g.performance != "" {
+				y += l.stepSpacing
+				text.Draw(screen, g.performance, g.font, l.textX, y, th.Muted)
+			}
+
+			if g.freeVariables > 0 {
+				y += l.stepSpacing
+				drawBox(screen, l.margin, y-top, boxWidth, l.stepHeight, bg, th)
+				text.Draw(screen, fmt.Sprintf("Degrees of freedom: %d", g.freeVariables), g.font, l.textX, y, fg)
+				y += l.stepSpacing
+				drawBox(screen, l.margin, y-top, boxWidth, l.stepHeight, bg, th)
+				text.Draw(screen, g.generalSolution, g.font, l.textX, y, fg)
+				y += l.stepSpacing
+				drawBox(screen, l.margin, y-top, boxWidth, l.stepHeight, bg, th)
+				text.Draw(screen, "Parametric form: "+g.parametricSolution, g.font, l.textX, y, fg)
+			}
+			if g.nullSpace != nil {
+				y += l.stepSpacing
+				drawBox(screen, l.margin, y-top, boxWidth, l.stepHeight, bg, th)
+				text.Draw(screen, solver.NullSpaceString(g.nullSpace, g.decimals), g.font, l.textX, y, fg)
+			}
+		}
+
+		if g.saveStatus != "" && g.solutionComplete {
+			text.Draw(screen, g.saveStatus, g.font, l.textX, y+g.px(40), th.Muted)
+		}
+	}
+
+	// Draw error message if any
+	if g.errorMsg != "" {
+		text.Draw(screen, g.errorMsg, g.font, l.margin, l.errorY(len(g.equations)), th.Error)
+	} else if g.preview != "" {
+		text.Draw(screen, "Live: "+g.preview, g.font, l.margin, l.errorY(len(g.equations)), th.Muted)
+	}
+
+	// Draw exit prompt if showing
+	if g.ShowExitPrompt {
+		overlayWidth := actualWidth
+		if overlayWidth < 1 {
+			overlayWidth = 1
+		}
+		overlayHeight := actualHeight
+		if overlayHeight < 1 {
+			overlayHeight = 1
+		}
+
+		overlay := ebiten.NewImage(overlayWidth, overlayHeight)
+		overlay.Fill(color.RGBA{0, 0, 0, 128})
+		opt := &ebiten.DrawImageOptions{}
+		screen.DrawImage(overlay, opt)
+
+		msg := "Solution complete. Press ESC to exit."
+		bound := text.BoundString(g.font, msg)
+		x := (overlayWidth - bound.Dx()) / 2
+		y := (overlayHeight - bound.Dy()) / 2
+		text.Draw(screen, msg, g.font, x, y, color.White)
+	}
+}
+
+// solveButton returns the Solve button, placed to the right of the first
+// equation field.
+func (g *Game) solveButton() Button {
+	l := g.layout()
+	return Button{x: 2*l.margin + l.fieldWidth, y: l.fieldTop, w: g.closeButton.w, h: l.fieldHeight, text: "Solve"}
+}
+
+// clearButton returns the Clear button, placed below the Solve button.
+func (g *Game) clearButton() Button {
+	b := g.solveButton()
+	b.y += g.layout().fieldSpacing
+	b.text = "Clear"
+	return b
+}
+
+// drawActionButton draws b lighter under the cursor and darker while it is
+// held down.
+func (g *Game) drawActionButton(screen *ebiten.Image, b Button, th *Theme) {
+	fill := th.Action[0]
+	if x, y := ebiten.CursorPosition(); b.Contains(x, y) {
+		fill = th.Action[1]
+		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			fill = th.Action[2]
+		}
+	}
+	drawBox(screen, b.x, b.y, b.w, b.h, fill, th)
+	g.drawButtonLabel(screen, b, th)
+}
+
+// drawButtonLabel centers a button's text on it.
+func (g *Game) drawButtonLabel(screen *ebiten.Image, b Button, th *Theme) {
+	bound := text.BoundString(g.font, b.text)
+	x := b.x + (b.w-bound.Dx())/2
+	y := b.y + (b.h+bound.Dy())/2
+	text.Draw(screen, b.text, g.font, x, y, th.ButtonText)
+}
+
+// fieldAt returns the equation field drawn at (x, y), or -1 if there is
+// none.
+func (g *Game) fieldAt(x, y int) int {
+	l := g.layout()
+	if x < l.margin || x >= l.margin+l.fieldWidth {
+		return -1
+	}
+	for i := range g.equations {
+		if top := l.fieldY(i); y >= top && y < top+l.fieldHeight {
+			return i
+		}
+	}
+	return -1
+}
+
+// trace returns the step trace of the matrix being shown, the exact one in
+// exact mode. Cramer's rule and the iterative methods show steps of their
+// own, which have no matrix after each of them.
+func (g *Game) trace() []solver.Step {
+	if g.method >= methodCramer && !g.invert {
+		return nil
+	}
+	if g.exactMatrix != nil {
+		return g.exactMatrix.Trace()
+	}
+	if g.matrix != nil {
+		return g.matrix.Trace()
+	}
+	return nil
+}
+
+// eliminatedColumn returns the column being eliminated at the current
+// animation step, or -1 when no column is being worked on.
+func (g *Game) eliminatedColumn() int {
+	if !g.solving || g.solutionComplete {
+		return -1
+	}
+	trace := g.trace()
+	if g.currentStep >= len(trace) {
+		return -1
+	}
+	return trace[g.currentStep].Column
+}
+
+// traceIndex returns the trace entry of the step shown last, which is the
+// final matrix once the animation has finished, or -1 if there is no trace.
+func (g *Game) traceIndex() int {
+	return min(g.currentStep, len(g.trace())-1)
+}
+
+// gridHeight returns the height of the matrix grid drawn above the steps.
+func (g *Game) gridHeight() int {
+	i := g.traceIndex()
+	if i < 0 {
+		return 0
+	}
+	l := g.layout()
+	return len(g.trace()[i].After)*l.stepHeight + l.stepSpacing - l.stepHeight
+}
+
+// drawMatrixGrid draws the matrix after a step as a grid of cells with its
+// top left corner at (x, y), with a bar before column bar, the first
+// right-hand side. The step's pivot is highlighted and the entries that
+// differ from prev, the matrix before the step, are marked.
+func (g *Game) drawMatrixGrid(screen *ebiten.Image, info solver.Step, prev [][]float64, bar, x, y int, th *Theme) {
+	l := g.layout()
+	for i, row := range info.After {
+		for j, v := range row {
+			fill := th.Field
+			if i == info.PivotRow && j == info.Column {
+				fill = th.Highlight
+			} else if prev != nil && prev[i][j] != v {
+				fill = th.ActiveField
+			}
+			cx, cy := x+j*l.cellWidth, y+i*l.stepHeight
+			if j >= bar {
+				cx += g.px(12)
+			}
+			drawBox(screen, cx, cy, l.cellWidth-g.px(4), l.stepHeight-g.px(4), fill, th)
+			text.Draw(screen, solver.FormatNumber(v, g.decimals), g.font, cx+g.px(8), cy+l.stepHeight-g.px(14), th.Text)
+		}
+	}
+	if len(info.After) > 0 {
+		barX := x + bar*l.cellWidth + g.px(2)
+		fillRect(screen, barX, y, g.px(2), len(info.After)*l.stepHeight-g.px(4), th.Text)
+	}
+}
+
+// markParseError underlines the part of an equation drawn at (x, y) that
+// g.parseError points at, with a caret below the field at its start.
+func (g *Game) markParseError(screen *ebiten.Image, eq string, x, y int, th *Theme) {
+	pe := g.parseError
+	if pe == nil || pe.Pos > len(eq) {
+		return
+	}
+	l := g.layout()
+	start := x + font.MeasureString(g.font, eq[:pe.Pos]).Ceil()
+	width := font.MeasureString(g.font, pe.Text).Ceil()
+	if width > 0 {
+		fillRect(screen, start, y+l.fieldHeight-g.px(6), width, g.px(2), th.Error)
+	}
+	caret := font.MeasureString(g.font, "^").Ceil()
+	text.Draw(screen, "^", g.font, start-caret/2, y+l.fieldHeight+l.textInset/2+g.px(4), th.Error)
+}
+
+// highlightVariable draws a marker behind every occurrence of variable in
+// an equation drawn at (x, y), so the variable being eliminated stands out
+// in the echoed input.
+func (g *Game) highlightVariable(screen *ebiten.Image, eq, variable string, x, y int) {
+	lower := strings.ToLower(eq)
+	for offset := 0; ; {
+		idx := strings.Index(lower[offset:], variable)
+		if idx < 0 {
+			return
+		}
+		idx += offset
+		offset = idx + len(variable)
+		if offset < len(eq) && eq[offset] >= '0' && eq[offset] <= '9' {
+			continue // x1 in x12
+		}
+		start := font.MeasureString(g.font, eq[:idx]).Ceil()
+		width := font.MeasureString(g.font, eq[idx:idx+len(variable)]).Ceil()
+		l := g.layout()
+		fillRect(screen, x+start-1, y+g.px(8), width+2, l.fieldHeight-g.px(12), g.theme().Highlight)
+	}
+}
+
+// drawBox fills a rectangle and, when the theme asks for it, outlines it.
+func drawBox(screen *ebiten.Image, x, y, w, h int, fill color.RGBA, th *Theme) {
+	if th.BorderWidth > 0 {
+		b := float32(th.BorderWidth)
+		vector.DrawFilledRect(screen, float32(x)-b, float32(y)-b, float32(w)+2*b, float32(h)+2*b, th.Border, false)
+	}
+	fillRect(screen, x, y, w, h, fill)
+}
+
+// fillRect fills a rectangle with a solid color.
+func fillRect(screen *ebiten.Image, x, y, w, h int, clr color.Color) {
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(w), float32(h), clr, false)
+}
+
+func (g *Game) theme() *Theme {
+	if g.highContrast {
+		return &highContrastTheme
+	}
+	if g.dark {
+		return &darkTheme
+	}
+	return &lightTheme
+}
+
+// toggleDark switches between the light and the dark theme and remembers
+// the choice for the next run.
+func (g *Game) toggleDark() {
+	g.dark = !g.dark
+	name := "light"
+	if g.dark {
+		name = "dark"
+	}
+	dir := g.solutionsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	os.WriteFile(filepath.Join(dir, themeFile), []byte(name+"\n"), 0644)
+}
+
+// loadTheme restores the theme saved by toggleDark.
+func (g *Game) loadTheme() {
+	data, err := os.ReadFile(filepath.Join(g.solutionsDir(), themeFile))
+	if err != nil {
+		return
+	}
+	g.dark = strings.TrimSpace(string(data)) == "dark"
+}
+
+func (g *Game) layout() screenLayout {
+	l := normalLayout
+	if g.highContrast {
+		l = largeLayout
+	}
+	return l.scaled(g.uiScale())
+}
+
+// changeSpeed moves the animation speed by delta, staying between manual
+// and the fastest speed.
+func (g *Game) changeSpeed(delta int) {
+	g.stepSpeed = min(max(g.stepSpeed+delta, 0), len(stepDelays))
+}
+
+// inputMatrix returns a copy of the augmented matrix of the last solve as
+// parsed, before any elimination, for the other methods to work on.
+func (g *Game) inputMatrix() *solver.Matrix {
+	return g.original.Clone()
+}
+
+// rankSummary is the line under the solution with the ranks and, for a
+// square system, the determinant.
+func (g *Game) rankSummary() string {
+	if g.determinant == "" {
+		return g.rank
+	}
+	return g.rank + ", " + g.determinant
+}
+
+// showMethodResult shows the answer of Cramer's rule or an iteration, the
+// values it found or, if it failed, why, in place of the elimination's.
+func (g *Game) showMethodResult(values []float64, err error) {
+	if err != nil {
+		g.methodFailed = true
+		g.verdict = g.method.String() + " failed"
+		g.solution = "No answer from " + g.method.String() + ": " + err.Error()
+		return
+	}
 	g.steps = append(g.steps, "\nSolution:")
-	g.solution = fmt.Sprintf("x = %.2f, y = %.2f, z = %.2f",
-		g.matrix.data[0][3], g.matrix.data[1][3], g.matrix.data[2][3])
+	g.solution = g.matrix.ValuesString(values)
+	g.residuals = solver.Residuals(g.coefficients, values)
+}
+
+// verdict names the kind of a system for the banner above its solution,
+// given how many of its unknowns are free, n - rank(A).
+func verdict(kind solver.Kind, free int) string {
+	switch {
+	case kind == solver.None:
+		return "No solution (inconsistent)"
+	case kind == solver.Unique:
+		return "Unique solution"
+	case free == 1:
+		return "Infinitely many solutions (1 free variable)"
+	}
+	return fmt.Sprintf("Infinitely many solutions (%d free variables)", free)
+}
+
+// residualSummary lists the residual of each equation for the line under
+// the solution, e.g. "Residuals (Ax - b): 0, 0, 0".
+func (g *Game) residualSummary() string {
+	values := make([]string, len(g.residuals))
+	for i, r := range g.residuals {
+		values[i] = solver.FormatNumber(r, g.decimals)
+	}
+	return "Residuals (Ax - b): " + strings.Join(values, ", ")
+}
+
+// speedLabel describes the animation speed for the header.
+func (g *Game) speedLabel() string {
+	if g.stepSpeed == 0 {
+		return "Speed: manual"
+	}
+	return fmt.Sprintf("Speed: %d/%d", g.stepSpeed, len(stepDelays))
+}
+
+// shownSteps returns how many steps of the solution are on screen.
+func (g *Game) shownSteps() int {
+	return min(g.currentStep+1, len(g.steps))
+}
+
+// progressLabel returns "Step 4 of 11" while a solution is shown, or ""
+// when there is none.
+func (g *Game) progressLabel() string {
+	if !g.solving || len(g.steps) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Step %d of %d", g.shownSteps(), len(g.steps))
+}
+
+// toggleHighContrast switches between the normal look and the
+// accessibility mode with high-contrast colors, larger text and larger
+// boxes and buttons.
+func (g *Game) toggleHighContrast() {
+	g.highContrast = !g.highContrast
+	g.applyScale()
+}
+
+// expandInlineSystem spreads a whole system typed into one field as
+// "eq1; eq2; eq3" over one field per equation. It reports false, with the
+// error already set, when the inline system can't be spread out.
+func (g *Game) expandInlineSystem() bool {
+	for i, eq := range g.equations {
+		if !strings.Contains(eq, ";") {
+			continue
+		}
+		for j, other := range g.equations {
+			if j != i && other != "" {
+				g.inputError(j, "Clear the other fields when entering the whole system on one line")
+				return false
+			}
+		}
+		if err := g.setEquations(splitSystem(eq)); err != nil {
+			g.inputError(i, err.Error())
+			return false
+		}
+		return true
+	}
+	return true
+}
+
+// inputError puts the game back into input mode after a failed solve: any
+// previous solution is cleared and the offending field, if known, is
+// focused and highlighted until the next solve.
+func (g *Game) inputError(field int, msg string) {
+	g.errorMsg = msg
+	g.errorField = field
+	g.parseError = nil
+	if field >= 0 {
+		g.activeEquation = field
+	}
+	g.solving = false
+	g.solutionComplete = false
+	g.solutionDisplayDone = false
+	g.keepWindowOpen = false
+	g.steps = nil
+	g.currentStep = 0
+	g.solution = ""
+	g.verdict = ""
+	g.methodFailed = false
+	g.freeVariables = 0
+	g.generalSolution = ""
+	g.parametricSolution = ""
+	g.nullSpace = nil
+}
+
+// reset empties every equation field and drops the current solution,
+// stopping its animation, so a new system can be entered.
+func (g *Game) reset() {
+	for i := range g.equations {
+		g.equations[i] = ""
+	}
+	g.undo = nil
+	g.cursors = nil
+	g.activeEquation = 0
+	if g.matrixMode {
+		g.cells = cellsFromEquations(g.equations)
+		g.cellRow, g.cellCol = 0, 0
+	}
+	g.errorMsg = ""
+	g.errorField = -1
+	g.solving = false
+	g.solutionComplete = false
+	g.solutionDisplayDone = false
+	g.keepWindowOpen = false
+	g.solutionTimer = 0
+	g.ShowExitPrompt = false
+	g.steps = nil
+	g.currentStep = 0
+	g.stepDelay = 0
+	g.scroll = 0
+	g.paused = false
+	g.solution = ""
+	g.verdict = ""
+	g.methodFailed = false
+	g.freeVariables = 0
+	g.generalSolution = ""
+	g.parametricSolution = ""
+	g.nullSpace = nil
+	g.determinant = ""
+	g.rank = ""
+	g.warning = ""
+	g.dependencies = nil
+	g.performance = ""
+	g.saveStatus = ""
+	g.report = ""
+	g.matrix = nil
+	g.exactMatrix = nil
+	g.inverse = nil
+	g.coefficients = nil
+	g.original = nil
+	g.originalEquations = nil
+}
+
+// solve parses the equation fields and solves the system with the current
+// method.
+func (g *Game) solve() {
+	defer g.recoverSolve()
+	g.clearResult()
+
+	if g.matrixMode {
+		m, row, err := g.cellMatrix()
+		if err != nil {
+			g.inputError(row, err.Error())
+			return
+		}
+		g.originalEquations = append([]string(nil), g.equations...)
+		g.solveSystem(m)
+		return
+	}
+
+	if !g.expandInlineSystem() {
+		return
+	}
+
+	m, errs := solver.Parse(g.equations)
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		msg := "Error in " + err.Error()
+		if g.equations[i] == "" {
+			msg = fmt.Sprintf("Please enter equation %d", i+1)
+		}
+		if more := solver.CountErrors(errs[i+1:]); more > 0 {
+			msg += fmt.Sprintf(" (and %d more)", more)
+		}
+		g.inputError(i, msg)
+		errors.As(err, &g.parseError)
+		return
+	}
+	g.originalEquations = append([]string(nil), g.equations...)
+	g.solveSystem(m)
+}
+
+// resolve solves the last system again, e.g. after the method changed,
+// starting from its matrix as parsed rather than from the fields, which
+// may have been edited since. It does nothing while no solution is shown
+// and in invert mode, where the method doesn't apply.
+func (g *Game) resolve() {
+	if !g.solving || g.original == nil || g.invert {
+		return
+	}
+	defer g.recoverSolve()
+	m := g.original.Clone()
+	equations := g.originalEquations
+	g.clearResult()
+	g.originalEquations = equations
+	g.solveSystem(m)
+}
+
+// recoverSolve turns a panic while solving into an error message.
+func (g *Game) recoverSolve() {
+	if r := recover(); r != nil {
+		log.Printf("Recovered from error in solve: %v", r)
+		g.inputError(-1, "An error occurred while solving")
+	}
+}
+
+// clearResult drops the previous solution before solving again.
+func (g *Game) clearResult() {
+	g.errorMsg = ""
+	g.errorField = -1
+	g.solution = ""
+	g.verdict = ""
+	g.methodFailed = false
+	g.freeVariables = 0
+	g.generalSolution = ""
+	g.parametricSolution = ""
+	g.nullSpace = nil
+	g.determinant = ""
+	g.rank = ""
+	g.warning = ""
+	g.dependencies = nil
+	g.performance = ""
+	g.residuals = nil
+	g.exactMatrix = nil
+	g.inverse = nil
+	g.coefficients = nil
+	g.original = nil
+	g.originalEquations = nil
+	g.report = ""
+	g.saveStatus = ""
+}
+
+// solveSystem eliminates the augmented matrix m of the system in the
+// fields with the current method and shows the steps and the result.
+func (g *Game) solveSystem(m *solver.Matrix) {
+	g.matrix = m
+	g.matrix.SetRowLabels(g.rowLabels.Prefix, g.rowLabels.Base)
+	g.matrix.SetDebug(g.debug)
+	g.matrix.SetDecimals(g.decimals)
+	g.matrix.SetTolerance(g.tol)
+	// Every step is drawn as a grid from its snapshot.
+	g.matrix.SetSnapshots(true)
+	if g.invert {
+		aug, err := g.matrix.AugmentIdentity()
+		if err != nil {
+			g.inputError(-1, "Cannot invert: "+err.Error())
+			return
+		}
+		g.matrix = aug
+	}
+
+	g.currentStep = 0
+	g.stepDelay = 0
+	g.scroll = 0
+	g.paused = false
+	g.solving = true
+	g.solutionComplete = false
+	g.solutionDisplayDone = false
+	g.ShowExitPrompt = false
+
+	initialMatrix := g.matrix.GetMatrixString()
+	g.original = g.matrix.Clone()
+	g.coefficients = g.original.Data()
+	var exact *solver.RatMatrix
+	// The exact elimination has a single right-hand side and always
+	// reduces fully, so inversion and the other methods use floats.
+	if g.exact && !g.invert && g.method == methodGaussJordan {
+		exact = solver.ExactMatrix(g.matrix)
+		initialMatrix = exact.GetMatrixString()
+	}
+	if g.method == methodBackSubstitution && !g.invert {
+		g.steps = g.matrix.ForwardElimination()
+	} else {
+		_, g.steps, _ = g.matrix.Solve()
+	}
+
+	if g.matrix.Anomaly() != "" {
+		msg := "Debug: elimination halted, " + g.matrix.Anomaly()
+		path, err := writeDebugDump(g.solutionsDir(), g.equations, initialMatrix, g.matrix, g.steps)
+		if err != nil {
+			log.Printf("Error writing debug dump: %v", err)
+		} else {
+			msg += " (state written to " + path + ")"
+		}
+		g.inputError(-1, msg)
+		return
+	}
+
+	g.solutionKind = g.matrix.Classify()
+	// Recorded before the other methods or the exact elimination replace
+	// the steps, as the operation counts are the float elimination's.
+	if g.metricsPath != "" {
+		if err := writeMetrics(g.metricsPath, eliminationMetrics(g.matrix, len(g.steps))); err != nil {
+			log.Printf("Error writing metrics: %v", err)
+		}
+	}
+	otherMethod := g.method >= methodCramer && !g.invert
+	var methodValues []float64
+	var methodErr error
+	if otherMethod {
+		// The elimination above still classifies the system, but its
+		// operations and its answer are not what the other methods show.
+		switch g.method {
+		case methodCramer:
+			methodValues, g.steps, methodErr = solver.CramerSteps(g.inputMatrix())
+		case methodJacobi, methodGaussSeidel:
+			methodValues, g.steps, methodErr = solver.IterativeSteps(g.inputMatrix(), g.method == methodGaussSeidel)
+		}
+	}
+	rank, augmented := g.matrix.Rank()
+	g.rank = fmt.Sprintf("rank(A) = %d, rank([A|b]) = %d", rank, augmented)
+	if det, ok := g.matrix.Determinant(); ok {
+		g.determinant = "det = " + solver.FormatNumber(det, g.decimals)
+	}
+	if c := g.matrix.Stats().ConditionEstimate(); c > solver.IllConditioned && exact == nil {
+		g.warning = fmt.Sprintf("Warning: ill-conditioned (pivot ratio %.1e), result may be inaccurate", c)
+	}
+	if !g.invert {
+		g.dependencies = solver.EquationNotes(g.original)
+	}
+	// The other methods and the exact elimination don't do these
+	// operations.
+	if (g.method < methodCramer || g.invert) && exact == nil {
+		g.performance = g.matrix.Stats().Summary()
+	}
+	if exact != nil {
+		g.steps = exact.GaussianElimination()
+		g.solutionKind = exact.Classify()
+		g.exactMatrix = exact
+	}
+	if !g.invert {
+		g.verdict = verdict(g.solutionKind, g.matrix.CoefficientColumns()-g.matrix.CoefficientRank())
+	}
+	if g.invert {
+		g.showInverse()
+	} else if otherMethod {
+		g.showMethodResult(methodValues, methodErr)
+	} else if g.solutionKind != solver.Unique {
+		homogeneous := g.original.IsHomogeneous()
+		switch {
+		case g.solutionKind == solver.None:
+			g.solution = "No solution (inconsistent system)"
+		case homogeneous:
+			g.solution = "Nontrivial solutions exist (infinitely many)"
+		default:
+			g.solution = "Infinitely many solutions"
+		}
+		if s := g.matrix.Singular(); s != nil {
+			g.solution += fmt.Sprintf(" (singular at step %d, column %s)", s.Step, g.matrix.VariableName(s.Column))
+		}
+		if g.solutionKind == solver.Infinite {
+			g.freeVariables = g.matrix.CoefficientColumns() - g.matrix.CoefficientRank()
+			reduced := g.matrix
+			if g.method == methodBackSubstitution {
+				reduced = g.matrix.ReducedCopy()
+			}
+			g.generalSolution = reduced.GeneralSolution()
+			g.parametricSolution = reduced.ParametricSolution()
+			if homogeneous {
+				g.nullSpace = reduced.NullSpaceBasis()
+			}
+		}
+	} else {
+		if g.method == methodBackSubstitution {
+			g.steps = append(g.steps, g.matrix.BackSubstitute()...)
+		}
+		g.steps = append(g.steps, "\nSolution:")
+		if g.original.IsHomogeneous() {
+			g.solution = g.original.TrivialSolution()
+		} else if exact != nil {
+			g.solution = exact.SolutionString()
+		} else {
+			g.solution = g.matrix.SolutionString()
+		}
+		g.residuals = solver.Residuals(g.coefficients, g.matrix.SolutionVector())
+	}
+
+	if g.showLU && !g.invert {
+		g.steps = append(g.steps, solver.LUSteps(g.inputMatrix())...)
+	}
 
 	// Start the solution timer
 	g.solutionTimer = displayTime
 	g.keepWindowOpen = true
 
-	// Handle file operations
-	err := os.MkdirAll("solutions", 0755)
+	g.report = g.formatReport(initialMatrix)
+	if !g.reopening && !g.noSave {
+		g.saveReport()
+		g.recordHistory()
+	}
+}
+
+// writeDebugDump records the state of a halted debug elimination so the
+// run can be reproduced: the input, the matrix before and at the point of
+// failure, and every operation performed so far.
+func writeDebugDump(dir string, equations []string, initialMatrix string, m *solver.Matrix, steps []string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Debug dump generated at: %s\n", time.Now().Format("2006-01-02 15:04:05")))
+	b.WriteString("Anomaly: " + m.Anomaly() + "\n\n")
+	b.WriteString("Input Equations:\n")
+	for i, eq := range equations {
+		b.WriteString(fmt.Sprintf("Equation %d: %s\n", i+1, eq))
+	}
+	b.WriteString("\nInitial Matrix:\n")
+	b.WriteString(initialMatrix)
+	b.WriteString("\nOperations Before Halt:\n")
+	for _, step := range steps {
+		b.WriteString(step + "\n")
+	}
+	b.WriteString("\nMatrix At Halt (full precision):\n")
+	for _, row := range m.Data() {
+		b.WriteString(fmt.Sprintln(row))
+	}
+
+	name := "gaussian_debug_" + time.Now().Format("2006-01-02_15-04-05")
+	path := filepath.Join(dir, name+".txt")
+	// A batch can halt on several systems within the same second.
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); err != nil {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s_%d.txt", name, n))
+	}
+	return path, os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// solveMetrics is the machine-readable summary written by -metrics-json.
+type solveMetrics struct {
+	Solution     string  `json:"solution"`
+	Steps        int     `json:"steps"`
+	Swaps        int     `json:"swaps"`
+	Scalings     int     `json:"scalings"`
+	RowAdditions int     `json:"row_additions"`
+	ElapsedNanos int64   `json:"elapsed_ns"`
+	Determinant  float64 `json:"determinant"`
+}
+
+// eliminationMetrics summarizes the elimination of m, which produced steps
+// steps, so that the step count and the operations come from the same run.
+func eliminationMetrics(m *solver.Matrix, steps int) solveMetrics {
+	stats := m.Stats()
+	return solveMetrics{
+		Solution:     m.Classify().String(),
+		Steps:        steps,
+		Swaps:        stats.Swaps,
+		Scalings:     stats.Scalings,
+		RowAdditions: stats.RowAdds,
+		ElapsedNanos: stats.Elapsed.Nanoseconds(),
+		Determinant:  stats.Determinant,
+	}
+}
+
+// writeMetrics writes metrics as one JSON object to path, or to stdout
+// when path is "-". Files get one object per line so repeated solves append.
+func writeMetrics(path string, metrics solveMetrics) error {
+	b, err := json.Marshal(metrics)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(b)
+	return err
+}
+
+// formatReport renders the solved system as the text written to the
+// solution file.
+func (g *Game) formatReport(initialMatrix string) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Solution generated at: %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
+
+	b.WriteString("Input Equations:\n")
+	for i, eq := range g.originalEquations {
+		b.WriteString(fmt.Sprintf("Equation %d: %s\n", i+1, eq))
+	}
+
+	b.WriteString("\nInitial Matrix:\n")
+	b.WriteString(initialMatrix)
+
+	b.WriteString("\nSolution Steps:\n")
+	// The trace has one matrix for each elimination and back substitution
+	// step, which come first in g.steps; the solution, inverse and LU
+	// lines after them have none.
+	steps := g.steps
+	if g.verbose {
+		trace := g.trace()
+		for i, info := range trace[:min(len(trace), len(steps))] {
+			b.WriteString(steps[i] + "\n")
+			b.WriteString(solver.FormatRows(info.After, g.matrix.CoefficientColumns(), g.decimals) + "\n")
+		}
+		steps = steps[min(len(trace), len(steps)):]
+	}
+	for _, step := range steps {
+		b.WriteString(step + "\n")
+	}
+
+	b.WriteString("\nFinal Matrix:\n")
+	if g.exactMatrix != nil {
+		b.WriteString(g.exactMatrix.GetMatrixString())
+	} else {
+		b.WriteString(g.matrix.GetMatrixString())
+	}
+
+	b.WriteString("\n" + g.solution + "\n")
+	if g.rank != "" {
+		b.WriteString(g.rank + "\n")
+	}
+	if g.warning != "" {
+		b.WriteString(g.warning + "\n")
+	}
+	for _, note := range g.dependencies {
+		b.WriteString(note + "\n")
+	}
+	if g.determinant != "" {
+		b.WriteString("Determinant: " + strings.TrimPrefix(g.determinant, "det = ") + "\n")
+	}
+	for i, r := range g.residuals {
+		b.WriteString(fmt.Sprintf("Equation %d residual: %s\n", i+1, solver.FormatNumber(r, g.decimals)))
+	}
+	if g.performance != "" {
+		b.WriteString(g.performance + "\n")
+	}
+	if g.freeVariables > 0 {
+		b.WriteString(fmt.Sprintf("Degrees of freedom: %d\n", g.freeVariables))
+		b.WriteString(g.generalSolution + "\n")
+		b.WriteString("Parametric form: " + g.parametricSolution + "\n")
+	}
+	if g.nullSpace != nil {
+		b.WriteString(solver.NullSpaceString(g.nullSpace, g.decimals) + "\n")
+	}
+	return b.String()
+}
+
+// solutionsDir returns the directory solutions are written to.
+func (g *Game) solutionsDir() string {
+	if g.saveDir != "" {
+		return g.saveDir
+	}
+	return outputDir
+}
+
+// saveReport writes the report to the first writable output directory and
+// tells the user where it went. If nothing is writable the report is only
+// kept in memory.
+func (g *Game) saveReport() {
+	dirs := []string{g.solutionsDir(), filepath.Join(os.TempDir(), outputDir)}
+	report := g.report
+	if g.ascii {
+		report = asciiText(report)
+	}
+	path, err := saveSolution(report, dirs)
+	if err == nil {
+		jsonPath := strings.TrimSuffix(path, ".txt") + ".json"
+		if err := g.writeJSONSolution(jsonPath); err != nil {
+			log.Printf("Error saving JSON solution: %v", err)
+		}
+	}
+	switch {
+	case err != nil:
+		log.Printf("Error saving solution: %v", err)
+		g.saveStatus = "Could not save the solution file; it is kept in memory only"
+	case path != filepath.Join(dirs[0], filepath.Base(path)):
+		g.saveStatus = "Solutions directory not writable, saved to " + path
+	default:
+		g.saveStatus = "Saved to " + path
+	}
+}
+
+// solutionJSON is the machine-readable form of a solution, saved next to
+// each text report.
+type solutionJSON struct {
+	Equations     []string           `json:"equations"`
+	Coefficients  [][]float64        `json:"coefficients"`
+	Steps         []string           `json:"steps"`
+	FinalMatrix   [][]float64        `json:"final_matrix"`
+	Kind          string             `json:"kind"`
+	Solution      map[string]float64 `json:"solution,omitempty"`
+	FreeVariables int                `json:"free_variables,omitempty"`
+	Rank          int                `json:"rank"`
+	AugmentedRank int                `json:"augmented_rank"`
+	Determinant   *float64           `json:"determinant,omitempty"`
+	Condition     float64            `json:"condition_estimate,omitempty"`
+	Inverse       [][]float64        `json:"inverse,omitempty"`
+	Residuals     []float64          `json:"residuals,omitempty"`
+	NullSpace     [][]float64        `json:"null_space,omitempty"`
+}
+
+// MarshalSolution encodes the last solve: the input, the parsed augmented
+// matrix, the operations, the reduced matrix and, if there is a unique
+// solution, the value of every unknown.
+func (g *Game) MarshalSolution() ([]byte, error) {
+	if g.matrix == nil || g.coefficients == nil {
+		return nil, errors.New("no solution to export")
+	}
+	sol := solutionJSON{
+		Equations:     g.equations,
+		Coefficients:  g.coefficients,
+		Kind:          g.solutionKind.String(),
+		FreeVariables: g.freeVariables,
+		NullSpace:     g.nullSpace,
+	}
+	if g.inverse != nil {
+		sol.Inverse = g.inverse.Data()
+	} else if g.solutionKind == solver.Unique {
+		sol.Solution = g.matrix.SolutionValues()
+		sol.Residuals = g.residuals
+	}
+	return encodeSolution(sol, g.matrix, g.steps)
+}
+
+// encodeSolution adds to sol the steps and what it says about the reduced
+// matrix m, the final matrix, the ranks, the condition estimate and the
+// determinant, and encodes it.
+func encodeSolution(sol solutionJSON, m *solver.Matrix, steps []string) ([]byte, error) {
+	sol.Steps = []string{}
+	for _, step := range steps {
+		// Headings such as "\nSolution:" and "\nBack substitution:" only
+		// divide the on-screen list, and the newline only spaces it out.
+		if strings.HasPrefix(step, "\n") && strings.HasSuffix(step, ":") {
+			continue
+		}
+		sol.Steps = append(sol.Steps, strings.TrimPrefix(step, "\n"))
+	}
+	sol.FinalMatrix = m.Data()
+	sol.Rank, sol.AugmentedRank = m.Rank()
+	sol.Condition = m.Stats().ConditionEstimate()
+	if det, ok := m.Determinant(); ok {
+		sol.Determinant = &det
+	}
+	return json.MarshalIndent(sol, "", "  ")
+}
+
+func (g *Game) writeJSONSolution(path string) error {
+	b, err := g.MarshalSolution()
 	if err != nil {
-		log.Printf("Error creating solutions directory: %v", err)
+		return err
 	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
 
+// saveSolution writes report to a timestamped file in the first of dirs
+// that can be created and written, returning the file's path.
+func saveSolution(report string, dirs []string) (string, error) {
 	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	filename := filepath.Join("solutions", fmt.Sprintf("gaussian_solution_%s.txt", timestamp))
+	name := fmt.Sprintf("%s%s.txt", solutionFilePrefix, timestamp)
+
+	var lastErr error
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			lastErr = err
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(report), 0644); err != nil {
+			lastErr = err
+			continue
+		}
+		return path, nil
+	}
+	return "", lastErr
+}
 
-	f, err := os.Create(filename)
+// solutionFile describes a solution previously saved by saveSolution.
+type solutionFile struct {
+	Path          string
+	Timestamp     time.Time
+	FirstEquation string
+}
+
+const solutionFilePrefix = "gaussian_solution_"
+
+// listSolutionFiles returns the saved solutions in dir, newest first. A
+// missing directory simply has no solutions.
+func listSolutionFiles(dir string) ([]solutionFile, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		log.Printf("Error creating file: %v", err)
-		return
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
 	}
-	defer f.Close()
 
-	f.WriteString(fmt.Sprintf("Solution generated at: %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
+	files := []solutionFile{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, solutionFilePrefix) || filepath.Ext(name) != ".txt" {
+			continue
+		}
+		sf := solutionFile{Path: filepath.Join(dir, name)}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, solutionFilePrefix), ".txt")
+		if ts, err := time.ParseInLocation("2006-01-02_15-04-05", stamp, time.Local); err == nil {
+			sf.Timestamp = ts
+		} else if info, err := e.Info(); err == nil {
+			sf.Timestamp = info.ModTime()
+		}
 
-	f.WriteString("Input Equations:\n")
-	for i, eq := range g.equations {
-		f.WriteString(fmt.Sprintf("Equation %d: %s\n", i+1, eq))
+		if content, err := os.ReadFile(sf.Path); err == nil {
+			for _, line := range strings.Split(string(content), "\n") {
+				if eq, ok := strings.CutPrefix(line, "Equation 1: "); ok {
+					sf.FirstEquation = strings.TrimSpace(eq)
+					break
+				}
+			}
+		}
+		files = append(files, sf)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Timestamp.After(files[j].Timestamp)
+	})
+	return files, nil
+}
+
+// readSolutionEquations extracts the input equations from a saved solution
+// file. Files from older versions may lack the "Input Equations:" header,
+// so any "Equation N:" lines are accepted.
+func readSolutionEquations(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
 
-	f.WriteString("\nInitial Matrix:\n")
-	f.WriteString(initialMatrix)
+	equations := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if len(equations) > 0 && line == "" {
+			break
+		}
+		if !strings.HasPrefix(line, "Equation ") {
+			continue
+		}
+		if _, eq, ok := strings.Cut(line, ":"); ok {
+			equations = append(equations, strings.TrimSpace(eq))
+		}
+	}
+	if len(equations) == 0 {
+		return nil, fmt.Errorf("no input equations found in %s", filepath.Base(path))
+	}
+	return equations, nil
+}
 
-	f.WriteString("\nSolution Steps:\n")
-	for _, step := range g.steps {
-		f.WriteString(step + "\n")
+// reopenNext loads the next older saved solution (wrapping around to the
+// newest) and solves it again without writing another file.
+func (g *Game) reopenNext() {
+	files, err := listSolutionFiles(g.solutionsDir())
+	if err != nil || len(files) == 0 {
+		g.inputError(-1, "No saved solutions to reopen")
+		return
 	}
+	if g.reopenIndex >= len(files) {
+		g.reopenIndex = 0
+	}
+	path := files[g.reopenIndex].Path
+	g.reopenIndex++
 
-	f.WriteString("\nFinal Matrix:\n")
-	f.WriteString(g.matrix.GetMatrixString())
+	equations, err := readSolutionEquations(path)
+	if err != nil {
+		g.inputError(-1, err.Error())
+		return
+	}
+	if err := g.setEquations(equations); err != nil {
+		g.inputError(-1, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+		return
+	}
 
-	f.WriteString("\n" + g.solution + "\n")
+	g.reopening = true
+	g.solve()
+	g.reopening = false
+	if g.errorMsg == "" {
+		g.saveStatus = "Reopened " + path
+	}
 }
 
-func loadFont() (font.Face, error) {
+func loadFont(size float64) (font.Face, error) {
 	tt, err := opentype.Parse(goregular.TTF)
 	if err != nil {
 		return nil, err
 	}
 
 	return opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    fontSize,
+		Size:    size,
 		DPI:     72,
 		Hinting: font.HintingFull,
 	})
 }
 
 func NewGame() *Game {
-	font, err := loadFont()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	return &Game{
+	g := &Game{
 		equations:           make([]string, 3),
-		font:                font,
+		errorField:          -1,
+		decimals:            solver.DefaultDecimals,
+		stepSpeed:           defaultStepSpeed,
 		width:               minWidth,
 		height:              minHeight,
 		solutionComplete:    false,
@@ -577,23 +2511,202 @@ func NewGame() *Game {
 		keepWindowOpen:      false,
 		ShowExitPrompt:      false,
 		solutionDisplayDone: false,
-		closeButton: Button{
-			x:    screenWidth - 120, // Position in top right
-			y:    20,
-			w:    100,
-			h:    40,
-			text: "Close",
-		},
+		closeButton:         Button{text: "Close"}, // placed in the top right by applyScale
+	}
+	if _, err := g.fontFace(fontSize); err != nil {
+		log.Fatal(err)
+	}
+	g.applyScale()
+	return g
+}
+
+// headlessOptions are the flags the command-line, CSV and batch modes solve
+// and report with.
+type headlessOptions struct {
+	debug       bool
+	decimals    int
+	tol         solver.Tolerance
+	metricsPath string // -metrics-json, empty for none
+	dumpDir     string // where a halted -debug run is recorded, empty for nowhere
+	verbose     bool   // the matrix after every step in the batch report
+	ascii       bool   // the batch report in ASCII, see asciiText
+}
+
+// runCLI solves the system given on the command line, one equation per
+// argument or the whole system in one argument separated by ';', and
+// prints the steps and the result. Without arguments it solves
+// GAUSSIAN_SYSTEM, e.g. GAUSSIAN_SYSTEM="2x+y-z=8;-3x-y+2z=-11;-2x+y+2z=-3",
+// for containers and CI where there is no window. It returns the process
+// exit code: 1 if any equation fails to parse.
+func runCLI(args []string, opts headlessOptions, stdout, stderr io.Writer) int {
+	system := strings.Join(args, ";")
+	if len(args) == 0 {
+		system = os.Getenv(systemEnvVar)
+	}
+	equations := splitSystem(system)
+	m, errs := solver.Parse(equations)
+	if m == nil {
+		for _, err := range errs {
+			if err != nil {
+				fmt.Fprintln(stderr, "Error in "+err.Error())
+			}
+		}
+		if len(equations) == 0 {
+			fmt.Fprintln(stderr, "No equations given")
+		}
+		return 1
+	}
+	// -verbose is for saved reports, which the printed steps are not.
+	opts.verbose = false
+	return printSolve(m, equations, opts, stdout, stderr)
+}
+
+// printSolve eliminates m, parsed from equations, and prints the steps and
+// the result for the command-line modes. Unless opts.metricsPath is empty
+// it appends the metrics of the elimination like -metrics-json in the
+// window, and a halted -debug run is recorded in opts.dumpDir. With
+// opts.verbose every step is followed by the matrix after it. It returns
+// the process exit code.
+func printSolve(m *solver.Matrix, equations []string, opts headlessOptions, stdout, stderr io.Writer) int {
+	m.SetDebug(opts.debug)
+	m.SetDecimals(opts.decimals)
+	m.SetTolerance(opts.tol)
+	m.SetSnapshots(opts.verbose)
+
+	initialMatrix := m.GetMatrixString()
+	dependencies := solver.EquationNotes(m)
+	_, steps, err := m.Solve()
+	if opts.metricsPath != "" && m.Anomaly() == "" {
+		if err := writeMetrics(opts.metricsPath, eliminationMetrics(m, len(steps))); err != nil {
+			fmt.Fprintln(stderr, "Could not write metrics: "+err.Error())
+		}
+	}
+	for i, step := range steps {
+		fmt.Fprintln(stdout, step)
+		if opts.verbose && i < len(m.Trace()) {
+			fmt.Fprintln(stdout, solver.FormatRows(m.Trace()[i].After, m.CoefficientColumns(), opts.decimals))
+		}
+	}
+	for _, note := range dependencies {
+		fmt.Fprintln(stdout, note)
+	}
+	switch {
+	case m.Anomaly() != "":
+		msg := "Debug: " + err.Error()
+		if opts.dumpDir != "" {
+			if path, err := writeDebugDump(opts.dumpDir, equations, initialMatrix, m, steps); err != nil {
+				msg += " (could not write the debug dump: " + err.Error() + ")"
+			} else {
+				msg += " (state written to " + path + ")"
+			}
+		}
+		fmt.Fprintln(stderr, msg)
+		return 1
+	case errors.Is(err, solver.ErrNoSolution):
+		fmt.Fprintln(stdout, "No solution (inconsistent system)")
+	case errors.Is(err, solver.ErrInfiniteSolutions):
+		fmt.Fprintln(stdout, "Infinitely many solutions")
+		fmt.Fprintf(stdout, "Degrees of freedom: %d\n", m.CoefficientColumns()-m.CoefficientRank())
+		fmt.Fprintln(stdout, m.GeneralSolution())
+		fmt.Fprintln(stdout, "Parametric form: "+m.ParametricSolution())
+		if m.IsHomogeneous() {
+			fmt.Fprintln(stdout, solver.NullSpaceString(m.NullSpaceBasis(), opts.decimals))
+		}
+	default:
+		fmt.Fprintln(stdout, "\nSolution:")
+		if m.IsHomogeneous() {
+			fmt.Fprintln(stdout, m.TrivialSolution())
+		} else {
+			fmt.Fprintln(stdout, m.SolutionString())
+		}
 	}
+	return 0
 }
 
 func main() {
+	metricsPath := flag.String("metrics-json", "", "append solve metrics as JSON to this file (\"-\" for stdout)")
+	debug := flag.Bool("debug", false, "halt elimination at the first anomaly and write a debug dump")
+	decimals := flag.Int("precision", solver.DefaultDecimals, "decimals shown in matrices, steps and solutions")
+	file := flag.String("file", "", "pre-fill the equations from this file, one per line")
+	csvFile := flag.String("csv", "", "solve the augmented matrix in this CSV file, one row per equation, and print the steps")
+	saveDir := flag.String("output-dir", os.Getenv(outputDirEnvVar), "directory for solutions, LaTeX exports and the history (default \""+outputDir+"\", or $"+outputDirEnvVar+")")
+	noSave := flag.Bool("no-save", inBrowser, "don't write a report or update the history on each solve")
+	batch := flag.String("batch", "", "solve every system in this file, separated by blank lines, and print a report")
+	serve := flag.String("serve", "", "instead of opening a window, serve POST /solve on this address, e.g. :8080")
+	ascii := flag.Bool("ascii", false, "write <-> and -> instead of Unicode arrows in printed steps and saved reports")
+	verbose := flag.Bool("verbose", false, "write the augmented matrix after every row operation into saved reports")
+	epsilon := flag.Float64("epsilon", solver.DefaultEpsilon, "entries smaller than this in magnitude are treated as zero during elimination")
+	roundDigits := flag.Int("round-digits", solver.DefaultRoundDigits, "decimals entries are rounded to after each elimination pass, negative for no rounding")
+	flag.Parse()
+
+	var stdout io.Writer = os.Stdout
+	if *ascii {
+		stdout = asciiWriter{os.Stdout}
+	}
+
+	if *decimals < 0 || *decimals > maxDecimals {
+		fmt.Fprintf(os.Stderr, "-precision must be between 0 and %d\n", maxDecimals)
+		os.Exit(2)
+	}
+	if *epsilon <= 0 {
+		fmt.Fprintln(os.Stderr, "-epsilon must be positive")
+		os.Exit(2)
+	}
+	if *roundDigits == 0 {
+		fmt.Fprintln(os.Stderr, "-round-digits must be at least 1, or negative for no rounding")
+		os.Exit(2)
+	}
+	tol := solver.Tolerance{Epsilon: *epsilon, RoundDigits: *roundDigits}
+	dir := *saveDir
+	if dir == "" {
+		dir = outputDir
+	}
+	opts := headlessOptions{
+		debug:       *debug,
+		decimals:    *decimals,
+		tol:         tol,
+		metricsPath: *metricsPath,
+		dumpDir:     dir, // like Ctrl+L, written even with -no-save
+		verbose:     *verbose,
+		ascii:       *ascii,
+	}
+	if *csvFile != "" {
+		os.Exit(runCSV(*csvFile, opts, stdout, os.Stderr))
+	}
+	if *batch != "" {
+		if *noSave {
+			dir = ""
+		}
+		os.Exit(runBatch(*batch, dir, opts, stdout, os.Stderr))
+	}
+	if *serve != "" {
+		log.Printf("Serving POST /solve on %s", *serve)
+		log.Fatal(http.ListenAndServe(*serve, newServeMux(*debug, *decimals, tol, *metricsPath)))
+	}
+	if flag.NArg() > 0 || os.Getenv(systemEnvVar) != "" {
+		os.Exit(runCLI(flag.Args(), opts, stdout, os.Stderr))
+	}
+
 	ebiten.SetWindowSize(minWidth, minHeight)
 	ebiten.SetWindowTitle("Gaussian Elimination Solver")
 	ebiten.SetWindowResizable(true)
 	ebiten.SetWindowClosingHandled(true)
+	ebiten.SetScreenClearedEveryFrame(false)
 
 	game := NewGame()
+	game.metricsPath = *metricsPath
+	game.debug = *debug
+	game.decimals = *decimals
+	game.saveDir = *saveDir
+	game.noSave = *noSave
+	game.ascii = *ascii
+	game.verbose = *verbose
+	game.tol = tol
+	game.loadHistory()
+	game.loadTheme()
+	if *file != "" {
+		game.loadSystemFromFile(*file)
+	}
 	if err := ebiten.RunGame(game); err != nil {
 		if err == ebiten.Termination {
 			os.Exit(0) // Clean exit
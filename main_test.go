@@ -0,0 +1,1158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/saedarm/go-gaussian/solver"
+)
+
+func TestSplitSystem(t *testing.T) {
+	got := splitSystem(" 2x+y-z=8; -3x-y+2z=-11 ;\n-2x+y+2z=-3;")
+	want := []string{"2x+y-z=8", "-3x-y+2z=-11", "-2x+y+2z=-3"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("splitSystem = %q, want %q", got, want)
+	}
+}
+
+func TestSolveErrorReturnsToInput(t *testing.T) {
+	g := &Game{equations: []string{"x+y+z=6", "x-y=0", "2x+z=e"}, errorField: -1}
+	g.solving = true
+	g.solutionComplete = true
+	g.steps = []string{"stale"}
+	g.solution = "stale"
+
+	g.solve()
+
+	if g.errorField != 2 || g.activeEquation != 2 {
+		t.Errorf("errorField = %d, activeEquation = %d, want both 2", g.errorField, g.activeEquation)
+	}
+	if g.errorMsg == "" {
+		t.Error("errorMsg not set")
+	}
+	if pe := g.parseError; pe == nil || pe.Pos != 5 || pe.Text != "e" {
+		t.Errorf("parseError = %+v, want the %q at position 5 for the caret", pe, "e")
+	}
+	if g.solving || g.solutionComplete || g.steps != nil || g.solution != "" {
+		t.Errorf("stale solve state left behind: solving=%v complete=%v steps=%v solution=%q",
+			g.solving, g.solutionComplete, g.steps, g.solution)
+	}
+}
+
+func TestExpandInlineSystem(t *testing.T) {
+	g := &Game{equations: []string{"2x+y-z=8; -3x-y+2z=-11; -2x+y+2z=-3", "", ""}, errorField: -1}
+	if !g.expandInlineSystem() {
+		t.Fatalf("expandInlineSystem failed: %s", g.errorMsg)
+	}
+	want := []string{"2x+y-z=8", "-3x-y+2z=-11", "-2x+y+2z=-3"}
+	for i := range want {
+		if g.equations[i] != want[i] {
+			t.Errorf("equations[%d] = %q, want %q", i, g.equations[i], want[i])
+		}
+	}
+
+	g = &Game{equations: []string{"", "x=1; y=2", ""}, errorField: -1}
+	if !g.expandInlineSystem() {
+		t.Fatalf("expandInlineSystem failed: %s", g.errorMsg)
+	}
+	if len(g.equations) != 2 || g.equations[1] != "y=2" {
+		t.Errorf("equations = %q, want two fields", g.equations)
+	}
+
+	g = &Game{equations: []string{"x=1", "x=1; y=2", ""}, errorField: -1}
+	if g.expandInlineSystem() {
+		t.Error("expandInlineSystem accepted an inline system next to a filled field")
+	}
+	if g.errorField != 0 {
+		t.Errorf("errorField = %d, want 0", g.errorField)
+	}
+}
+
+func TestSaveSolutionFallsBack(t *testing.T) {
+	tmp := t.TempDir()
+	blocked := filepath.Join(tmp, "blocked")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fallback := filepath.Join(tmp, "fallback")
+
+	path, err := saveSolution("report", []string{blocked, fallback})
+	if err != nil {
+		t.Fatalf("saveSolution: %v", err)
+	}
+	if filepath.Dir(path) != fallback {
+		t.Errorf("saved to %s, want a file in %s", path, fallback)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "report" {
+		t.Errorf("file contains %q, want %q", b, "report")
+	}
+
+	if _, err := saveSolution("report", []string{blocked}); err == nil {
+		t.Error("saveSolution succeeded with no writable directory")
+	}
+}
+
+func TestListSolutionFiles(t *testing.T) {
+	dir := t.TempDir()
+	samples := map[string]string{
+		"gaussian_solution_2024-03-01_10-00-00.txt": "Input Equations:\nEquation 1: 2x+y-z=8\nEquation 2: x-y=-3\n",
+		"gaussian_solution_2024-03-02_09-30-15.txt": "Input Equations:\nEquation 1: x+y+z=6\n",
+		"notes.txt": "not a solution",
+	}
+	for name, content := range samples {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := listSolutionFiles(dir)
+	if err != nil {
+		t.Fatalf("listSolutionFiles: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2: %+v", len(files), files)
+	}
+	if filepath.Base(files[0].Path) != "gaussian_solution_2024-03-02_09-30-15.txt" {
+		t.Errorf("newest file = %s, want the 2024-03-02 solution first", files[0].Path)
+	}
+	if files[0].FirstEquation != "x+y+z=6" || files[1].FirstEquation != "2x+y-z=8" {
+		t.Errorf("first equations = %q, %q", files[0].FirstEquation, files[1].FirstEquation)
+	}
+	if files[1].Timestamp.Day() != 1 || files[1].Timestamp.Hour() != 10 {
+		t.Errorf("timestamp = %v, want 2024-03-01 10:00:00", files[1].Timestamp)
+	}
+
+	if files, err := listSolutionFiles(filepath.Join(dir, "missing")); err != nil || len(files) != 0 {
+		t.Errorf("missing dir: got %v, %v; want no files and no error", files, err)
+	}
+}
+
+func TestReadSolutionEquations(t *testing.T) {
+	dir := t.TempDir()
+	current := filepath.Join(dir, "current.txt")
+	os.WriteFile(current, []byte("Solution generated at: now\n\nInput Equations:\nEquation 1: 2x+y-z=8\nEquation 2: -3x-y+2z=-11\nEquation 3: -2x+y+2z=-3\n\nInitial Matrix:\n"), 0644)
+	old := filepath.Join(dir, "old.txt")
+	os.WriteFile(old, []byte("Equation 1: x=1\nEquation 2: y=2\nEquation 3: z=3\n"), 0644)
+	empty := filepath.Join(dir, "empty.txt")
+	os.WriteFile(empty, []byte("Final Matrix:\n"), 0644)
+
+	got, err := readSolutionEquations(current)
+	if err != nil || strings.Join(got, "|") != "2x+y-z=8|-3x-y+2z=-11|-2x+y+2z=-3" {
+		t.Errorf("current format: got %q, %v", got, err)
+	}
+	got, err = readSolutionEquations(old)
+	if err != nil || strings.Join(got, "|") != "x=1|y=2|z=3" {
+		t.Errorf("headerless format: got %q, %v", got, err)
+	}
+	if _, err := readSolutionEquations(empty); err == nil {
+		t.Error("expected an error for a file without equations")
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	for i := 0; i < 2; i++ {
+		if err := writeMetrics(path, solveMetrics{Solution: "unique", Steps: 7, Determinant: 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var got solveMetrics
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Steps != 7 || got.Solution != "unique" || got.Determinant != 2 {
+		t.Errorf("decoded %+v", got)
+	}
+}
+
+func TestMetricsOfEverySolve(t *testing.T) {
+	readMetrics := func(path string) []solveMetrics {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var all []solveMetrics
+		for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+			var m solveMetrics
+			if err := json.Unmarshal([]byte(line), &m); err != nil {
+				t.Fatal(err)
+			}
+			all = append(all, m)
+		}
+		return all
+	}
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "cli.json")
+	var stdout, stderr strings.Builder
+	if code := runCLI([]string{"x + y = 3", "x - y = 1"}, headlessOptions{decimals: 2, metricsPath: path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	_, steps, _ := solver.Solve([]string{"x + y = 3", "x - y = 1"})
+	if got := readMetrics(path); len(got) != 1 || got[0].Solution != "unique" || got[0].Steps != len(steps) || got[0].RowAdditions == 0 {
+		t.Errorf("command-line metrics %+v, want one unique solve of %d steps", got, len(steps))
+	}
+
+	path = filepath.Join(dir, "batch.json")
+	batch := filepath.Join(dir, "problems.txt")
+	if err := os.WriteFile(batch, []byte("x = 1\n\nx + y = 1\n2x + 2y = 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runBatch(batch, "", headlessOptions{decimals: 2, metricsPath: path}, &stdout, &stderr)
+	if got := readMetrics(path); len(got) != 2 || got[0].Solution != "unique" || got[1].Solution != "infinite" {
+		t.Errorf("batch metrics %+v, want one record per system", got)
+	}
+
+	// Cramer's rule replaces the steps; the count stays the elimination's.
+	path = filepath.Join(dir, "window.json")
+	g := &Game{equations: []string{"x + y = 3", "x - y = 1"}, errorField: -1, reopening: true, method: methodCramer, metricsPath: path}
+	g.solve()
+	if got := readMetrics(path); len(got) != 1 || got[0].Steps != len(g.matrix.Trace()) {
+		t.Errorf("window metrics %+v, want the %d steps of the elimination", got, len(g.matrix.Trace()))
+	}
+}
+
+func TestInsertDecimalPoint(t *testing.T) {
+	tests := []struct {
+		eq   string
+		pos  int
+		want string
+	}{
+		{"", 0, "."},
+		{"1", 1, "1."},
+		{"1.5x + 0", 8, "1.5x + 0."},
+		{"1.5", 3, "1.5"},
+		{"2x + .", 6, "2x + ."},
+		{"15x = 2", 1, "1.5x = 2"},
+		{"1.5x = 2", 1, "1.5x = 2"},
+		{"15.2x = 2", 1, "15.2x = 2"},
+		{"1.5x + 2 = 3", 8, "1.5x + 2. = 3"},
+	}
+	for _, tt := range tests {
+		if got := insertDecimalPoint(tt.eq, tt.pos); got != tt.want {
+			t.Errorf("insertDecimalPoint(%q, %d) = %q, want %q", tt.eq, tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestEditAtCursor(t *testing.T) {
+	g := &Game{equations: []string{"2x + z = 5", ""}}
+	if c := g.cursor(); c != len(g.equations[0]) {
+		t.Fatalf("new caret at %d, want the end", c)
+	}
+	g.setCursor(5)
+	g.typeText("y")
+	if g.equations[0] != "2x + yz = 5" || g.cursor() != 6 {
+		t.Errorf("insert: %q with the caret at %d", g.equations[0], g.cursor())
+	}
+	g.undoEdit()
+	if g.equations[0] != "2x + z = 5" {
+		t.Errorf("undo of an insert: %q", g.equations[0])
+	}
+
+	g.setCursor(99)
+	if g.cursor() != len(g.equations[0]) {
+		t.Errorf("caret past the end: %d", g.cursor())
+	}
+	g.setCursor(-1)
+	if g.cursor() != 0 {
+		t.Errorf("caret before the start: %d", g.cursor())
+	}
+
+	// Each field keeps its own caret.
+	g.activeEquation = 1
+	g.typeText("x")
+	g.activeEquation = 0
+	if g.cursor() != 0 {
+		t.Errorf("caret in field 1 moved to %d by typing in field 2", g.cursor())
+	}
+
+	// A caret at the end follows text pasted or recalled into the field.
+	g.activeEquation = 1
+	g.pasteSystem("= 3")
+	if g.cursor() != len(g.equations[1]) {
+		t.Errorf("caret at %d after paste, want the end of %q", g.cursor(), g.equations[1])
+	}
+}
+
+func TestRunCLI(t *testing.T) {
+	var stdout, stderr strings.Builder
+	code := runCLI([]string{"2x+y-z=8", "-3x-y+2z=-11", "-2x+y+2z=-3"}, headlessOptions{decimals: solver.DefaultDecimals}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr %q", code, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.HasPrefix(out, "Starting Gaussian Elimination...\n") || !strings.HasSuffix(out, "\nSolution:\nx = 2, y = 3, z = -1\n") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	stdout.Reset()
+	if code := runCLI([]string{"x + y = 1; x + y = 2"}, headlessOptions{decimals: solver.DefaultDecimals}, &stdout, &stderr); code != 0 {
+		t.Errorf("inconsistent system: exit code %d, want 0", code)
+	}
+	if !strings.HasSuffix(stdout.String(), "No solution (inconsistent system)\n") {
+		t.Errorf("inconsistent system: unexpected output:\n%s", stdout.String())
+	}
+
+	stderr.Reset()
+	if code := runCLI([]string{"x + y = 1", "x + y"}, headlessOptions{decimals: solver.DefaultDecimals}, &stdout, &stderr); code != 1 {
+		t.Errorf("parse error: exit code %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "equation 2") {
+		t.Errorf("parse error: stderr %q does not name the equation", stderr.String())
+	}
+}
+
+func TestRunCLIFromEnv(t *testing.T) {
+	t.Setenv(systemEnvVar, "x + y = 3; x - y = 1")
+	var stdout, stderr strings.Builder
+	if code := runCLI(nil, headlessOptions{decimals: solver.DefaultDecimals}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d, stderr %q", code, stderr.String())
+	}
+	if !strings.HasSuffix(stdout.String(), "\nSolution:\nx = 2, y = 1\n") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	// Arguments take precedence over the environment.
+	stdout.Reset()
+	if code := runCLI([]string{"x = 5"}, headlessOptions{decimals: solver.DefaultDecimals}, &stdout, &stderr); code != 0 || !strings.HasSuffix(stdout.String(), "x = 5\n") {
+		t.Errorf("arguments with %s set: exit code %d, output:\n%s", systemEnvVar, code, stdout.String())
+	}
+
+	t.Setenv(systemEnvVar, "")
+	stderr.Reset()
+	if code := runCLI(nil, headlessOptions{decimals: solver.DefaultDecimals}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "No equations given") {
+		t.Errorf("empty %s: exit code %d, stderr %q", systemEnvVar, code, stderr.String())
+	}
+}
+
+func TestRunCLIWritesDebugDump(t *testing.T) {
+	dir := t.TempDir()
+	var stdout, stderr strings.Builder
+	opts := headlessOptions{debug: true, decimals: solver.DefaultDecimals, dumpDir: dir}
+	if code := runCLI([]string{"x + y = 2", "1e-13y = 1e-13"}, opts, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "(state written to "+dir) {
+		t.Errorf("exit code %d, stderr %q; want the halt and the dump's path", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := runCLI([]string{"x + y = 1", "2x + 2y = 2"}, opts, &stdout, &stderr); code != 0 || stderr.Len() != 0 {
+		t.Errorf("dependent system with -debug: exit code %d, stderr %q; want it solved", code, stderr.String())
+	}
+}
+
+func TestMarshalSolution(t *testing.T) {
+	g := &Game{equations: []string{"2x + y - z = 8", "-3x - y + 2z = -11", "-2x + y + 2z = -3"}, errorField: -1, reopening: true}
+	g.solve()
+	if g.errorMsg != "" {
+		t.Fatalf("solve failed: %s", g.errorMsg)
+	}
+	b, err := g.MarshalSolution()
+	if err != nil {
+		t.Fatalf("MarshalSolution: %v", err)
+	}
+
+	var got solutionJSON
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, b)
+	}
+	if got.Kind != "unique" || math.Abs(got.Solution["x"]-2) > 1e-9 || math.Abs(got.Solution["z"]+1) > 1e-9 {
+		t.Errorf("kind %q, solution %v; want unique with x = 2, z = -1", got.Kind, got.Solution)
+	}
+	if got.Coefficients[1][0] != -3 || got.Coefficients[1][3] != -11 {
+		t.Errorf("coefficients = %v, want the parsed system", got.Coefficients)
+	}
+	if got.FinalMatrix[0][0] != 1 || got.FinalMatrix[0][1] != 0 {
+		t.Errorf("final matrix = %v, want the reduced matrix", got.FinalMatrix)
+	}
+	if len(got.Steps) == 0 || got.Steps[len(got.Steps)-1] == "\nSolution:" {
+		t.Errorf("steps = %q, want the operations without the display heading", got.Steps)
+	}
+	if !strings.Contains(string(b), `"x": 2`) {
+		t.Errorf("solution values are not JSON numbers:\n%s", b)
+	}
+	if got.Rank != 3 || got.AugmentedRank != 3 {
+		t.Errorf("ranks = %d, %d; want 3, 3", got.Rank, got.AugmentedRank)
+	}
+	if got.Determinant == nil || math.Abs(*got.Determinant+1) > 1e-9 {
+		t.Errorf("determinant = %v, want -1", got.Determinant)
+	}
+	if g.determinant != "det = -1" || !strings.Contains(g.report, "Determinant: -1\n") {
+		t.Errorf("determinant shown as %q; report:\n%s", g.determinant, g.report)
+	}
+	if len(got.Residuals) != 3 || !strings.Contains(g.report, "Equation 3 residual: 0\n") {
+		t.Errorf("residuals = %v; report:\n%s", got.Residuals, g.report)
+	}
+
+	g = &Game{equations: []string{"x + y = 3", "x - y = 1"}, errorField: -1, reopening: true, method: methodBackSubstitution}
+	g.solve()
+	b, err = g.MarshalSolution()
+	if err != nil {
+		t.Fatalf("MarshalSolution with back substitution: %v", err)
+	}
+	got = solutionJSON{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range got.Steps {
+		if strings.HasPrefix(step, "\n") || step == "Back substitution:" {
+			t.Errorf("steps = %q, want no headings or leading newlines", got.Steps)
+			break
+		}
+	}
+	if !slices.Contains(got.Steps, "From L2: y = 1") {
+		t.Errorf("steps = %q, want the back substitution", got.Steps)
+	}
+
+	if _, err := (&Game{}).MarshalSolution(); err == nil {
+		t.Error("MarshalSolution succeeded without a solve")
+	}
+}
+
+func TestFieldAt(t *testing.T) {
+	g := &Game{equations: make([]string, 3)}
+	tests := []struct{ x, y, want int }{
+		{30, 110, 0},
+		{419, 139, 0},
+		{30, 150, -1}, // between fields
+		{200, 230, 2},
+		{10, 110, -1},
+		{430, 110, -1},
+		{30, 300, -1},
+	}
+	for _, tt := range tests {
+		if got := g.fieldAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("fieldAt(%d, %d) = %d, want %d", tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	g.highContrast = true
+	if got := g.fieldAt(560, 140); got != 0 {
+		t.Errorf("high contrast: fieldAt(560, 140) = %d, want 0", got)
+	}
+}
+
+func TestSolveButtonPlacement(t *testing.T) {
+	for _, highContrast := range []bool{false, true} {
+		g := &Game{equations: make([]string, 3), highContrast: highContrast, closeButton: Button{w: 100}}
+		b := g.solveButton()
+		if b.x+b.w > screenWidth {
+			t.Errorf("highContrast=%v: solve button ends at x=%d, past the window", highContrast, b.x+b.w)
+		}
+		if g.fieldAt(b.x, b.y) >= 0 {
+			t.Errorf("highContrast=%v: solve button overlaps an equation field", highContrast)
+		}
+		if !b.Contains(b.x+b.w/2, b.y+b.h/2) {
+			t.Errorf("highContrast=%v: solve button does not contain its center", highContrast)
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	g := &Game{equations: []string{"x + y = 3", "x - y = 1"}, errorField: -1, reopening: true}
+	g.solve()
+	g.currentStep = 2
+	g.activeEquation = 1
+	g.reset()
+
+	if g.equations[0] != "" || g.equations[1] != "" || len(g.equations) != 2 {
+		t.Errorf("equations = %q, want two empty fields", g.equations)
+	}
+	if g.solving || g.solutionComplete || g.keepWindowOpen || g.steps != nil || g.currentStep != 0 || g.solution != "" {
+		t.Error("solution state not cleared")
+	}
+	if g.activeEquation != 0 || g.errorField != -1 || g.errorMsg != "" {
+		t.Errorf("activeEquation %d, errorField %d, errorMsg %q; want 0, -1, empty", g.activeEquation, g.errorField, g.errorMsg)
+	}
+	if g.eliminatedColumn() != -1 {
+		t.Error("a column is still highlighted after reset")
+	}
+}
+
+func TestPasteSystem(t *testing.T) {
+	g := &Game{equations: make([]string, 3), errorField: -1}
+	g.pasteSystem("2x + y = 3\r\nx - y = 0\n")
+	if len(g.equations) != 2 || g.equations[0] != "2x + y = 3" || g.equations[1] != "x - y = 0" {
+		t.Errorf("two lines: equations = %q", g.equations)
+	}
+
+	g.pasteSystem("x = 1\ny = 2\nz = 3\nw = 4")
+	if len(g.equations) != 4 || g.equations[3] != "w = 4" {
+		t.Errorf("four lines: equations = %q, want four fields", g.equations)
+	}
+
+	g = &Game{equations: []string{"", "2x"}, activeEquation: 1, errorField: -1}
+	g.pasteSystem(" + y = 3\n")
+	if g.equations[1] != "2x+ y = 3" {
+		t.Errorf("single line: active field = %q, want the text appended", g.equations[1])
+	}
+
+	g.pasteSystem(strings.Repeat("x = 1\n", 7))
+	if g.errorMsg == "" || len(g.equations) != 2 {
+		t.Errorf("seven lines: errorMsg %q, %d fields; want an error and the fields unchanged", g.errorMsg, len(g.equations))
+	}
+}
+
+func TestLoadSystemFromFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "practice.txt")
+	content := "# warm-up\n2x + y = 3\n\n  x - y = 0  \n# done\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := &Game{equations: make([]string, 3), errorField: -1}
+	g.loadSystemFromFile(path)
+	if g.errorMsg != "" {
+		t.Fatalf("errorMsg = %q", g.errorMsg)
+	}
+	if len(g.equations) != 2 || g.equations[0] != "2x + y = 3" || g.equations[1] != "x - y = 0" {
+		t.Errorf("equations = %q", g.equations)
+	}
+
+	bad := filepath.Join(dir, "bad.txt")
+	if err := os.WriteFile(bad, []byte("x + y = 1\n# comment\nx ++ y = 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g = &Game{equations: make([]string, 3), errorField: -1}
+	g.loadSystemFromFile(bad)
+	if !strings.HasPrefix(g.errorMsg, "bad.txt line 3: ") || g.errorField != 1 {
+		t.Errorf("errorMsg %q, errorField %d; want line 3 reported on field 1", g.errorMsg, g.errorField)
+	}
+	if g.equations[1] != "x ++ y = 2" {
+		t.Errorf("bad line not loaded for editing: %q", g.equations)
+	}
+
+	g = &Game{equations: make([]string, 3), errorField: -1}
+	g.loadSystemFromFile(filepath.Join(dir, "missing.txt"))
+	if g.errorMsg == "" || len(g.equations) != 3 {
+		t.Errorf("missing file: errorMsg %q, equations %q", g.errorMsg, g.equations)
+	}
+}
+
+func TestScrollClamps(t *testing.T) {
+	g := &Game{equations: make([]string, 3), solving: true, steps: make([]string, 30), currentStep: 29}
+	g.Layout(800, 600)
+	limit := g.getContentHeight() - 600
+	if limit <= 0 {
+		t.Fatalf("content height %d fits on screen; the test needs more steps", g.getContentHeight())
+	}
+
+	g.scrollBy(100)
+	if g.scroll != 100 {
+		t.Errorf("scroll = %d, want 100", g.scroll)
+	}
+	g.scrollBy(1 << 20)
+	if g.scroll != limit {
+		t.Errorf("scroll = %d, want it clamped to %d", g.scroll, limit)
+	}
+	g.scrollBy(-1 << 20)
+	if g.scroll != 0 {
+		t.Errorf("scroll = %d, want 0", g.scroll)
+	}
+
+	// A wider window gets a wider screen at the same scale, and a window
+	// twice the size both ways is drawn at twice the scale.
+	if w, h := g.Layout(1600, 600); w != 1600 || h != 600 || g.uiScale() != 1 {
+		t.Errorf("Layout(1600, 600) = %d×%d at scale %v, want 1600×600 at 1", w, h, g.uiScale())
+	}
+	if g.closeButton.x+g.closeButton.w != 1600-20 {
+		t.Errorf("close button ends at %d, want it at the right edge", g.closeButton.x+g.closeButton.w)
+	}
+	if w, h := g.Layout(1600, 1200); w != 1600 || h != 1200 || g.uiScale() != 2 {
+		t.Errorf("Layout(1600, 1200) = %d×%d at scale %v, want 1600×1200 at 2", w, h, g.uiScale())
+	}
+	if w, _ := g.Layout(400, 300); w != 800 {
+		t.Errorf("Layout(400, 300) width = %d, want it kept at minWidth", w)
+	}
+	if g.scroll > g.maxScroll() {
+		t.Errorf("scroll %d past the end after resizing the window", g.scroll)
+	}
+
+	// Clearing the solution shrinks the content back to one screen.
+	g.reset()
+	if g.maxScroll() != 0 || g.getContentHeight() != minHeight {
+		t.Errorf("after reset: content height %d, max scroll %d", g.getContentHeight(), g.maxScroll())
+	}
+}
+
+func TestUIScale(t *testing.T) {
+	tests := []struct {
+		width, height int
+		deviceScale   float64
+		want          float64
+	}{
+		{800, 600, 1, 1},
+		{400, 300, 1, 1},
+		{800, 600, 2, 2},
+		{1000, 750, 1, 1.25},
+		{1100, 900, 1, 1.25},
+		{1600, 600, 1, 1}, // wide but short: more room, not larger text
+		{10000, 10000, 2, maxScale},
+	}
+	for _, tt := range tests {
+		if got := uiScale(tt.width, tt.height, tt.deviceScale); got != tt.want {
+			t.Errorf("uiScale(%d, %d, %v) = %v, want %v", tt.width, tt.height, tt.deviceScale, got, tt.want)
+		}
+	}
+
+	g := &Game{equations: make([]string, 3), scale: 2}
+	l := g.layout()
+	if l.fieldWidth != 2*normalLayout.fieldWidth || l.fieldY(1) != 2*normalLayout.fieldY(1) {
+		t.Errorf("layout at scale 2 = %+v, want normalLayout doubled", l)
+	}
+	if got := g.fieldAt(2*normalLayout.margin+10, 2*normalLayout.fieldTop+10); got != 0 {
+		t.Errorf("fieldAt in the scaled first field = %d, want 0", got)
+	}
+	g.applyScale()
+	if g.closeButton.w != 200 || g.closeButton.x+g.closeButton.w != 2*(screenWidth-20) {
+		t.Errorf("close button at scale 2 = %+v", g.closeButton)
+	}
+	if g.font == nil || g.faces[2*fontSize] == nil {
+		t.Error("font not loaded at twice the size")
+	}
+}
+
+func TestMatrixGridFollowsAnimation(t *testing.T) {
+	t.Chdir(t.TempDir()) // solve saves the report
+	g := &Game{equations: []string{"2x + y = 3", "x - y = 0"}}
+	g.solve()
+	trace := g.trace()
+
+	g.currentStep = 1
+	if i := g.traceIndex(); i != 1 {
+		t.Errorf("step 1 shows trace entry %d", i)
+	}
+	g.currentStep = len(g.steps) + 5
+	if i := g.traceIndex(); i != len(trace)-1 {
+		t.Errorf("finished animation shows trace entry %d, want the last (%d)", i, len(trace)-1)
+	}
+	if h := g.gridHeight(); h != 2*normalLayout.stepHeight+normalLayout.stepSpacing-normalLayout.stepHeight {
+		t.Errorf("grid height = %d for 2 rows", h)
+	}
+
+	g.reset()
+	if g.traceIndex() != -1 || g.gridHeight() != 0 {
+		t.Error("grid still shown after reset")
+	}
+}
+
+func TestPlaybackSteps(t *testing.T) {
+	t.Chdir(t.TempDir()) // solve saves the report
+	g := &Game{equations: []string{"2x + y = 3", "x - y = 0"}}
+	g.stepBack()
+	g.stepForward()
+	if g.currentStep != 0 {
+		t.Fatalf("stepping before solving moved to step %d", g.currentStep)
+	}
+
+	g.solve()
+	g.stepBack()
+	if g.currentStep != 0 {
+		t.Errorf("step back from the first step: currentStep = %d", g.currentStep)
+	}
+	g.stepForward()
+	g.stepForward()
+	g.stepBack()
+	if g.currentStep != 1 {
+		t.Errorf("currentStep = %d, want 1", g.currentStep)
+	}
+
+	for range g.steps {
+		g.stepForward()
+	}
+	if g.currentStep != len(g.steps) || !g.solutionComplete {
+		t.Fatalf("currentStep = %d of %d, complete %v", g.currentStep, len(g.steps), g.solutionComplete)
+	}
+	g.stepBack()
+	if g.currentStep != len(g.steps)-2 || g.solutionComplete {
+		t.Errorf("step back from the end: currentStep = %d of %d, complete %v", g.currentStep, len(g.steps), g.solutionComplete)
+	}
+}
+
+func TestProgressLabel(t *testing.T) {
+	g := &Game{equations: []string{"2x + y = 3", "x - y = 0"}, errorField: -1, reopening: true}
+	if got := g.progressLabel(); got != "" {
+		t.Errorf("progress before solving = %q, want none", got)
+	}
+	g.solve()
+	if got, want := g.progressLabel(), fmt.Sprintf("Step 1 of %d", len(g.steps)); got != want {
+		t.Errorf("progress = %q, want %q", got, want)
+	}
+	g.stepForward()
+	if got, want := g.progressLabel(), fmt.Sprintf("Step 2 of %d", len(g.steps)); got != want {
+		t.Errorf("progress = %q, want %q", got, want)
+	}
+	for range g.steps {
+		g.stepForward()
+	}
+	if got, want := g.progressLabel(), fmt.Sprintf("Step %d of %d", len(g.steps), len(g.steps)); got != want {
+		t.Errorf("progress at the end = %q, want %q", got, want)
+	}
+}
+
+func TestSkipToSolution(t *testing.T) {
+	g := &Game{equations: []string{"2x + y = 3", "x - y = 0"}, errorField: -1, reopening: true}
+	g.skipToSolution()
+	if g.solutionComplete {
+		t.Fatal("skipping before solving completed a solution")
+	}
+	g.solve()
+	g.paused = true
+	g.solutionTimer = 1
+	g.skipToSolution()
+	if g.currentStep != len(g.steps) || !g.solutionComplete || g.paused {
+		t.Errorf("currentStep = %d of %d, complete %v, paused %v", g.currentStep, len(g.steps), g.solutionComplete, g.paused)
+	}
+	if g.solutionTimer != displayTime || !g.keepWindowOpen {
+		t.Errorf("solution timer = %d, keepWindowOpen %v; want the full display time", g.solutionTimer, g.keepWindowOpen)
+	}
+}
+
+func TestResolveWithAnotherMethod(t *testing.T) {
+	g := &Game{equations: []string{"2x + y = 3", "x - y = 0"}, errorField: -1, reopening: true}
+	g.resolve()
+	if g.solving {
+		t.Fatal("resolve without a solution started solving")
+	}
+	g.solve()
+	want := g.solution
+	g.equations[0] = "2x + y = 30"
+	g.method = methodCramer
+	g.resolve()
+	if len(g.steps) == 0 || !strings.HasPrefix(g.steps[0], "Cramer's rule") {
+		t.Errorf("steps after switching to Cramer's rule: %q", g.steps)
+	}
+	if g.solution != want {
+		t.Errorf("solution = %q, want %q from the system as first solved", g.solution, want)
+	}
+	if g.equations[0] != "2x + y = 30" {
+		t.Errorf("fields changed to %q", g.equations)
+	}
+}
+
+func TestSolveShowsTheMethodsAnswer(t *testing.T) {
+	g := &Game{equations: []string{"x + 2y = 3", "3x + y = 4"}, errorField: -1, reopening: true, method: methodJacobi}
+	g.solve()
+	if !g.methodFailed || g.verdict != "Jacobi failed" || !strings.Contains(g.solution, "did not converge") || g.residuals != nil {
+		t.Errorf("diverging Jacobi: verdict %q, solution %q, residuals %v", g.verdict, g.solution, g.residuals)
+	}
+
+	g = &Game{equations: []string{"4x + y = 6", "x + 3y = 7"}, errorField: -1, reopening: true, method: methodGaussSeidel}
+	g.solve()
+	if g.methodFailed || g.verdict != "Unique solution" || g.solution != "x = 1, y = 2" || len(g.residuals) != 2 {
+		t.Errorf("converging Gauss-Seidel: verdict %q, solution %q, residuals %v", g.verdict, g.solution, g.residuals)
+	}
+
+	g = &Game{equations: []string{"x + y = 3", "2x + 2y = 6"}, errorField: -1, reopening: true, method: methodCramer}
+	g.solve()
+	if !g.methodFailed || g.verdict != "Cramer's rule failed" || g.generalSolution != "" {
+		t.Errorf("singular Cramer: verdict %q, solution %q, general solution %q", g.verdict, g.solution, g.generalSolution)
+	}
+	g.equations = []string{"2x + y = 1", "x - y = 1"}
+	g.solve()
+	if g.methodFailed || g.solution != "x = 2/3, y = -1/3" {
+		t.Errorf("Cramer: verdict %q, solution %q", g.verdict, g.solution)
+	}
+}
+
+func TestChangeSpeed(t *testing.T) {
+	g := &Game{stepSpeed: defaultStepSpeed}
+	for range 10 {
+		g.changeSpeed(1)
+	}
+	if g.stepSpeed != len(stepDelays) {
+		t.Errorf("speed = %d, want it capped at %d", g.stepSpeed, len(stepDelays))
+	}
+	for range 10 {
+		g.changeSpeed(-1)
+	}
+	if g.stepSpeed != 0 || g.speedLabel() != "Speed: manual" {
+		t.Errorf("speed = %d (%q), want manual", g.stepSpeed, g.speedLabel())
+	}
+}
+
+func TestIllConditionedWarning(t *testing.T) {
+	g := &Game{equations: []string{"x + y = 2", "x + 1.0000001y = 2.0000001"}, errorField: -1, reopening: true}
+	g.solve()
+	if c := g.matrix.Stats().ConditionEstimate(); c < 1e6 {
+		t.Errorf("condition estimate = %v, want about 1e7", c)
+	}
+	if !strings.HasPrefix(g.warning, "Warning: ill-conditioned") || !strings.Contains(g.report, g.warning) {
+		t.Errorf("warning %q, report:\n%s", g.warning, g.report)
+	}
+
+	g.equations = []string{"2x + y = 3", "x - y = 0"}
+	g.solve()
+	if g.warning != "" {
+		t.Errorf("well-conditioned system warned: %q", g.warning)
+	}
+}
+
+func TestOutputDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	g := &Game{equations: []string{"x + y = 2", "x - y = 0"}, saveDir: filepath.Join("out", "run1")}
+	g.solve()
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Errorf("default %s directory written with -output-dir set", outputDir)
+	}
+	if _, err := os.Stat(filepath.Join("out", "run1", historyFile)); err != nil {
+		t.Errorf("history not saved to the output directory: %v", err)
+	}
+	files, err := listSolutionFiles(g.solutionsDir())
+	if err != nil || len(files) == 0 {
+		t.Errorf("no report saved to the output directory (%v)", err)
+	}
+
+	g = &Game{equations: []string{"x + y = 2", "x - y = 0"}, noSave: true}
+	g.solve()
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Error("solve wrote files with -no-save")
+	}
+	if g.solutionsDir() != outputDir {
+		t.Errorf("solutionsDir() = %q, want the default %q", g.solutionsDir(), outputDir)
+	}
+}
+
+func TestDarkTheme(t *testing.T) {
+	t.Chdir(t.TempDir())
+	g := &Game{}
+	if g.theme() != &lightTheme {
+		t.Fatal("default theme is not the light theme")
+	}
+	g.toggleDark()
+	if g.theme() != &darkTheme {
+		t.Error("F10 did not switch to the dark theme")
+	}
+	g.highContrast = true
+	if g.theme() != &highContrastTheme {
+		t.Error("high contrast does not take precedence over the dark theme")
+	}
+
+	restored := &Game{}
+	restored.loadTheme()
+	if !restored.dark {
+		t.Error("dark theme not restored on the next run")
+	}
+	g.toggleDark()
+	restored.loadTheme()
+	if restored.dark {
+		t.Error("switching back to light was not saved")
+	}
+}
+
+func TestUndoEdit(t *testing.T) {
+	g := &Game{equations: []string{"", "y = 1"}}
+	for _, s := range []string{"2", "x", "=", "4"} {
+		g.typeText(s)
+	}
+	g.editActive("2x=")
+	g.undoEdit()
+	if g.equations[0] != "2x=4" {
+		t.Errorf("after undoing Backspace: %q, want %q", g.equations[0], "2x=4")
+	}
+	g.undoEdit()
+	g.undoEdit()
+	if g.equations[0] != "2x" {
+		t.Errorf("after undoing two keystrokes: %q, want %q", g.equations[0], "2x")
+	}
+
+	g.activeEquation = 1
+	g.undoEdit()
+	if g.equations[1] != "y = 1" {
+		t.Errorf("undo in a field without edits changed it to %q", g.equations[1])
+	}
+	g.activeEquation = 0
+	g.undoEdit()
+	g.undoEdit()
+	g.undoEdit()
+	if g.equations[0] != "" {
+		t.Errorf("undoing every edit left %q", g.equations[0])
+	}
+
+	for range undoLimit + 10 {
+		g.typeText("1")
+	}
+	if n := len(g.undo[0]); n != undoLimit {
+		t.Errorf("undo history has %d entries, want it capped at %d", n, undoLimit)
+	}
+	g.reset()
+	g.undoEdit()
+	if g.equations[0] != "" {
+		t.Errorf("undo after Clear restored %q", g.equations[0])
+	}
+}
+
+func TestCopyText(t *testing.T) {
+	g := &Game{equations: []string{"x + y = 3", "x - y = 1"}, errorField: -1, reopening: true}
+	if _, err := g.copyText(false); err == nil {
+		t.Error("copying before solving did not fail")
+	}
+	g.solve()
+	if got, err := g.copyText(false); err != nil || got != "x = 2, y = 1" {
+		t.Errorf("copyText(false) = %q, %v; want the solution", got, err)
+	}
+	got, err := g.copyText(true)
+	if err != nil || !strings.Contains(got, "Solution Steps:") || !strings.Contains(got, "x = 2, y = 1") {
+		t.Errorf("copyText(true) = %q, %v; want the full report", got, err)
+	}
+}
+
+func TestSolveWithBackSubstitution(t *testing.T) {
+	g := &Game{equations: []string{"x + y = 3", "2x + 2y = 6"}, errorField: -1, reopening: true, method: methodBackSubstitution}
+	g.solve()
+	if g.solutionKind != solver.Infinite || g.generalSolution != "x = 3 - y, y free" {
+		t.Errorf("kind %v, general solution %q", g.solutionKind, g.generalSolution)
+	}
+
+	g.equations = []string{"2x + y - z = 8", "-3x - y + 2z = -11", "-2x + y + 2z = -3"}
+	g.solve()
+	if g.solution != "x = 2, y = 3, z = -1" {
+		t.Errorf("solution = %q", g.solution)
+	}
+	// Every step up to the solution heading has its matrix in the trace,
+	// the "Back substitution:" heading included.
+	matrixSteps := slices.Index(g.steps, "\nSolution:")
+	if got := len(g.matrix.Trace()); got != matrixSteps {
+		t.Errorf("trace has %d entries for %d matrix steps %q", got, matrixSteps, g.steps)
+	}
+	for i, step := range g.steps[:matrixSteps] {
+		if strings.HasPrefix(step, "From L3") && g.matrix.Trace()[i].Column != 2 {
+			t.Errorf("step %d %q shows the matrix of column %d", i, step, g.matrix.Trace()[i].Column)
+		}
+	}
+}
+
+func TestSolveReportsDependentEquations(t *testing.T) {
+	g := &Game{equations: []string{"x + y = 3", "2x + 2y = 5"}, errorField: -1, reopening: true}
+	g.solve()
+	if g.solutionKind != solver.None {
+		t.Fatalf("classified as %v, want none", g.solutionKind)
+	}
+	if len(g.dependencies) != 1 || !strings.HasPrefix(g.dependencies[0], "Equations 1 and 2 conflict") {
+		t.Errorf("dependencies = %q, want equations 1 and 2 in conflict", g.dependencies)
+	}
+	if !strings.Contains(g.report, g.dependencies[0]) {
+		t.Errorf("report does not mention the conflict:\n%s", g.report)
+	}
+}
+
+func TestSolveReportsEmptyEquations(t *testing.T) {
+	g := &Game{equations: []string{"x - x = 0", "x + y = 2"}, errorField: -1, reopening: true}
+	g.solve()
+	if g.solutionKind != solver.Infinite {
+		t.Fatalf("classified as %v, want infinite", g.solutionKind)
+	}
+	if len(g.dependencies) != 1 || !strings.HasPrefix(g.dependencies[0], "Equation 1 reduces to 0 = 0") {
+		t.Errorf("notes = %q, want equation 1 flagged as empty", g.dependencies)
+	}
+	if !strings.Contains(g.report, g.dependencies[0]) {
+		t.Errorf("report does not mention the empty equation:\n%s", g.report)
+	}
+
+	g = &Game{equations: []string{"x + y = 2", "0 = 1"}, errorField: -1, reopening: true}
+	g.solve()
+	if g.solutionKind != solver.None {
+		t.Errorf("0 = 1 classified as %v, want none", g.solutionKind)
+	}
+}
+
+func TestSolveShowsVerdict(t *testing.T) {
+	tests := []struct {
+		equations []string
+		want      string
+	}{
+		{[]string{"x + y = 3", "x - y = 1"}, "Unique solution"},
+		{[]string{"x + y = 3", "2x + 2y = 5"}, "No solution (inconsistent)"},
+		{[]string{"x + y = 3", "2x + 2y = 6"}, "Infinitely many solutions (1 free variable)"},
+		{[]string{"x + y + z = 1", "2x + 2y + 2z = 2", "3x + 3y + 3z = 3"}, "Infinitely many solutions (2 free variables)"},
+	}
+	for _, tt := range tests {
+		g := &Game{equations: tt.equations, errorField: -1, reopening: true}
+		g.solve()
+		if g.verdict != tt.want {
+			t.Errorf("%q: verdict = %q, want %q", tt.equations, g.verdict, tt.want)
+		}
+	}
+
+	g := &Game{equations: []string{"2x + y = 0", "x + y = 0"}, errorField: -1, reopening: true, invert: true}
+	g.solve()
+	if g.verdict != "" {
+		t.Errorf("invert mode: verdict = %q, want none", g.verdict)
+	}
+}
+
+func TestSolveHomogeneous(t *testing.T) {
+	g := &Game{equations: []string{"x + y + z = 0", "x - y = 0", "y + 2z = 0"}, errorField: -1, reopening: true}
+	g.solve()
+	if want := "Only the trivial solution: x = y = z = 0"; g.solution != want {
+		t.Errorf("solution = %q, want %q", g.solution, want)
+	}
+	if g.nullSpace != nil {
+		t.Errorf("null space = %v for a nonsingular system", g.nullSpace)
+	}
+
+	g = &Game{equations: []string{"x + y = 0", "2x + 2y = 0"}, errorField: -1, reopening: true}
+	g.solve()
+	if !strings.HasPrefix(g.solution, "Nontrivial solutions exist") {
+		t.Errorf("solution = %q, want nontrivial solutions", g.solution)
+	}
+	if !strings.Contains(g.report, "Null space basis: (-1, 1)") {
+		t.Errorf("report does not list the null space basis:\n%s", g.report)
+	}
+
+	g = &Game{equations: []string{"x + y = 3", "2x + 2y = 6"}, errorField: -1, reopening: true}
+	g.solve()
+	if g.nullSpace != nil || strings.Contains(g.report, "Null space") {
+		t.Error("null space reported for an inhomogeneous system")
+	}
+}
+
+func TestSolveUsesTheUsersVariableNames(t *testing.T) {
+	tests := []struct {
+		equations []string
+		want      string
+	}{
+		{[]string{"a + b = 3", "a - b = -1"}, "a = 1, b = 2"},
+		{[]string{"x2 = 5", "x1 + x2 = 6"}, "x1 = 1, x2 = 5"},
+	}
+	for _, tt := range tests {
+		g := &Game{equations: tt.equations, errorField: -1, reopening: true}
+		g.solve()
+		if g.solution != tt.want {
+			t.Errorf("%q: solution = %q, want %q", tt.equations, g.solution, tt.want)
+		}
+	}
+
+	m, _ := solver.Parse([]string{"a + b = 0", "2a + 2b = 0"})
+	if got := m.VariableName(1); got != "b" {
+		t.Errorf("VariableName(1) = %q, want b", got)
+	}
+	if got := m.VariableName(2); got != "z" {
+		t.Errorf("VariableName(2) past the named columns = %q, want the classic z", got)
+	}
+}
+
+func TestPerformanceInReport(t *testing.T) {
+	g := &Game{equations: []string{"2x + y = 3", "x - y = 0"}, errorField: -1, reopening: true}
+	g.solve()
+	if !strings.HasPrefix(g.performance, "Row operations: ") || !strings.Contains(g.report, g.performance) {
+		t.Errorf("performance %q missing from the report:\n%s", g.performance, g.report)
+	}
+	g.method = methodCramer
+	g.resolve()
+	if g.performance != "" {
+		t.Errorf("performance = %q for Cramer's rule, want none", g.performance)
+	}
+}
+
+func TestResolveReportsTheSolvedEquations(t *testing.T) {
+	t.Chdir(t.TempDir())
+	g := &Game{equations: []string{"x + y = 2", "x - y = 0"}, errorField: -1}
+	g.solve()
+	g.equations[1] = "x - y = 4"
+	g.method = methodBackSubstitution
+	g.resolve()
+	if !strings.Contains(g.report, "Equation 2: x - y = 0\n") {
+		t.Errorf("report after an edit and a method change does not show the solved equations:\n%s", g.report)
+	}
+	history, err := loadHistory(filepath.Join(g.solutionsDir(), historyFile))
+	if want := []string{"x + y = 2; x - y = 0"}; err != nil || !slices.Equal(history, want) {
+		t.Errorf("history = %q, %v; want %q", history, err, want)
+	}
+}
+
+func TestVerboseReportShowsEveryMatrix(t *testing.T) {
+	equations := []string{"x + y = 3", "x - y = 1"}
+	g := &Game{equations: equations, errorField: -1, reopening: true}
+	g.solve()
+	if strings.Contains(g.report, "[1 1 | 3]\n[0 -2 | -2]\n") {
+		t.Errorf("report without -verbose lists the intermediate matrices:\n%s", g.report)
+	}
+
+	g = &Game{equations: equations, errorField: -1, reopening: true, verbose: true}
+	g.solve()
+	steps := g.report[strings.Index(g.report, "Solution Steps:"):strings.Index(g.report, "Final Matrix:")]
+	if strings.Count(steps, "\n[1 1 | 3]\n") != 3 || !strings.Contains(steps, "→ L2\n[1 1 | 3]\n[0 -2 | -2]\n") {
+		t.Errorf("verbose report does not show the matrix after each step:\n%s", steps)
+	}
+
+	g.method = methodBackSubstitution
+	g.resolve()
+	steps = g.report[strings.Index(g.report, "Solution Steps:"):strings.Index(g.report, "Final Matrix:")]
+	for _, want := range []string{
+		"Back substitution:\n[1 1 | 3]\n[0 1 | 1]\n",
+		"From L2: y = 1\n[1 1 | 3]\n[0 1 | 1]\n",
+		"From L1: x = 3 - (1)(1) = 2\n[1 0 | 2]\n[0 1 | 1]\n",
+	} {
+		if !strings.Contains(steps, want) {
+			t.Errorf("verbose back substitution report missing %q:\n%s", want, steps)
+		}
+	}
+
+	g.method = methodCramer
+	g.resolve()
+	if strings.Contains(g.report, "[0 -2 | -2]") {
+		t.Errorf("verbose report shows elimination matrices for Cramer's rule:\n%s", g.report)
+	}
+}
+
+func TestWriteDebugDump(t *testing.T) {
+	inf := solver.NewMatrix(3, 4)
+	for i, row := range [][]float64{{1, 0, 0, 1}, {0, 1, 0, math.Inf(1)}, {0, 0, 1, 1}} {
+		for j, v := range row {
+			inf.Set(i, j, v)
+		}
+	}
+	inf.SetDebug(true)
+	steps := inf.GaussianElimination()
+	path, err := writeDebugDump(t.TempDir(), []string{"x=1", "y=2", "z=3"}, "[initial]\n", inf, steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(path)
+	for _, want := range []string{"Anomaly: non-finite", "Equation 2: y=2", "Operations Before Halt:", "+Inf"} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("debug dump missing %q:\n%s", want, b)
+		}
+	}
+}
+
+func TestSolveKeepsOriginal(t *testing.T) {
+	g := &Game{equations: []string{"2x + y = 5", "x - y = 1"}, errorField: -1, reopening: true}
+	g.solve()
+	if g.original == nil || !reflect.DeepEqual(g.original.Data(), [][]float64{{2, 1, 5}, {1, -1, 1}}) {
+		t.Errorf("original after solve = %v, want the parsed system", g.original)
+	}
+	in := g.inputMatrix()
+	in.Set(0, 0, 99)
+	if g.original.At(0, 0) == 99 {
+		t.Error("inputMatrix shares its entries with the original")
+	}
+}
+
+func TestResidualSummary(t *testing.T) {
+	g := &Game{equations: []string{"x + y = 3", "x - y = 1"}, errorField: -1, reopening: true}
+	g.solve()
+	if got, want := g.residualSummary(), "Residuals (Ax - b): 0, 0"; got != want {
+		t.Errorf("residualSummary = %q, want %q", got, want)
+	}
+	g.equations = []string{"x + y = 3", "2x + 2y = 6"}
+	g.solve()
+	if g.residuals != nil {
+		t.Errorf("residuals = %v for a system without a unique solution", g.residuals)
+	}
+}
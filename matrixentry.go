@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/saedarm/go-gaussian/solver"
+	"golang.org/x/image/font"
+)
+
+// In matrix mode the fields are replaced by an n x (n+1) grid of cells
+// holding the augmented matrix, one row per equation, so a system can be
+// typed as numbers without writing out the variables. Every edit writes
+// the row back into its equation field as text, so the report, the history
+// and equation mode see the same system.
+
+// toggleMatrixMode switches between typing equations and typing the
+// augmented matrix cell by cell.
+func (g *Game) toggleMatrixMode() {
+	g.matrixMode = !g.matrixMode
+	if g.matrixMode {
+		g.cells = cellsFromEquations(g.equations)
+		g.cellRow, g.cellCol = g.activeEquation, 0
+	} else {
+		g.activeEquation = g.cellRow
+	}
+}
+
+// cellsFromEquations fills an n x (n+1) grid from the equations, one row
+// per equation. Equations that don't parse leave their row empty, and so do
+// all of them if the system has more unknowns than equations.
+func cellsFromEquations(equations []string) [][]string {
+	n := len(equations)
+	cells := make([][]string, n)
+	coeffs, vars, _ := solver.ParseSystem(equations)
+	for i := range cells {
+		cells[i] = make([]string, n+1)
+		if coeffs[i] == nil || len(vars) > n {
+			continue
+		}
+		for j, v := range coeffs[i] {
+			cells[i][j] = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+	}
+	return cells
+}
+
+// rowEquation writes a row of cells as an equation, e.g. "2x + y - z = 8",
+// keeping the numbers as typed. It returns "" if a cell is not a number.
+func rowEquation(row []string) string {
+	last := len(row) - 1
+	var b strings.Builder
+	for j, cell := range row[:last] {
+		v, err := solver.ParseNumber(cell)
+		if err != nil {
+			return ""
+		}
+		if v == 0 {
+			continue
+		}
+		num, negative := strings.CutPrefix(strings.TrimPrefix(cell, "+"), "-")
+		switch {
+		case b.Len() == 0 && negative:
+			b.WriteString("-")
+		case negative:
+			b.WriteString(" - ")
+		case b.Len() > 0:
+			b.WriteString(" + ")
+		}
+		if v != 1 && v != -1 {
+			b.WriteString(num)
+		}
+		b.WriteString(solver.VariableName(j))
+	}
+	if _, err := solver.ParseNumber(row[last]); err != nil {
+		return ""
+	}
+	if b.Len() == 0 {
+		b.WriteString("0x")
+	}
+	return b.String() + " = " + row[last]
+}
+
+// cellMatrix parses the cells into an augmented matrix. On failure it
+// returns the row of the first cell that is empty or not a number.
+func (g *Game) cellMatrix() (*solver.Matrix, int, error) {
+	n := len(g.cells)
+	m := solver.NewMatrix(n, n+1)
+	for i, row := range g.cells {
+		for j, cell := range row {
+			if cell == "" {
+				return nil, i, fmt.Errorf("Please fill in row %d, column %d", i+1, j+1)
+			}
+			v, err := solver.ParseNumber(cell)
+			if err != nil {
+				return nil, i, fmt.Errorf("Error in row %d, column %d: %v %q", i+1, j+1, err, cell)
+			}
+			m.Set(i, j, v)
+		}
+	}
+	return m, 0, nil
+}
+
+// editCell replaces the text of the active cell and updates its equation.
+func (g *Game) editCell(s string) {
+	g.cells[g.cellRow][g.cellCol] = s
+	g.equations[g.cellRow] = rowEquation(g.cells[g.cellRow])
+}
+
+// moveCell moves the active cell by the given number of rows and columns,
+// staying inside the grid.
+func (g *Game) moveCell(rows, cols int) {
+	g.cellRow = max(0, min(len(g.cells)-1, g.cellRow+rows))
+	g.cellCol = max(0, min(len(g.cells[0])-1, g.cellCol+cols))
+}
+
+// nextCell moves to the next cell along the row, wrapping to the start of
+// the next row and from the last cell back to the first.
+func (g *Game) nextCell() {
+	g.cellCol++
+	if g.cellCol == len(g.cells[0]) {
+		g.cellCol = 0
+		g.cellRow = (g.cellRow + 1) % len(g.cells)
+	}
+}
+
+// handleCellInput handles the keys of matrix mode and reports whether one
+// was pressed. Space, and the arrows while a solution is shown, are left to
+// handleInput.
+func (g *Game) handleCellInput() bool {
+	if len(g.cells) != len(g.equations) {
+		g.cells = cellsFromEquations(g.equations)
+	}
+	g.moveCell(0, 0)
+	cell := g.cells[g.cellRow][g.cellCol]
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) {
+		if cell != "" {
+			g.editCell(cell[:len(cell)-1])
+		}
+		return true
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadEnter) {
+		g.nextCell()
+		return true
+	}
+	if !g.solving {
+		moves := map[ebiten.Key][2]int{
+			ebiten.KeyLeft: {0, -1}, ebiten.KeyRight: {0, 1},
+			ebiten.KeyUp: {-1, 0}, ebiten.KeyDown: {1, 0},
+		}
+		for k, d := range moves {
+			if inpututil.IsKeyJustPressed(k) {
+				g.moveCell(d[0], d[1])
+				return true
+			}
+		}
+	}
+
+	typed := ""
+	for k := ebiten.Key0; k <= ebiten.Key9; k++ {
+		if inpututil.IsKeyJustPressed(k) && !ebiten.IsKeyPressed(ebiten.KeyShift) {
+			typed += strconv.Itoa(int(k - ebiten.Key0))
+		}
+	}
+	for k := ebiten.KeyNumpad0; k <= ebiten.KeyNumpad9; k++ {
+		if inpututil.IsKeyJustPressed(k) {
+			typed += strconv.Itoa(int(k - ebiten.KeyNumpad0))
+		}
+	}
+	symbols := map[ebiten.Key]string{
+		ebiten.KeyMinus: "-", ebiten.KeyNumpadSubtract: "-",
+		ebiten.KeyPeriod: ".", ebiten.KeyNumpadDecimal: ".",
+		ebiten.KeySlash: "/", ebiten.KeyNumpadDivide: "/",
+		ebiten.KeyE: "e",
+	}
+	for k, s := range symbols {
+		if inpututil.IsKeyJustPressed(k) {
+			typed += s
+		}
+	}
+	if typed != "" {
+		g.editCell(cell + typed)
+		return true
+	}
+	return false
+}
+
+// cellWidth returns the width of a matrix-mode cell, the field width
+// shared by the n+1 columns.
+func (g *Game) cellWidth() int {
+	return g.layout().fieldWidth / (len(g.cells) + 1)
+}
+
+// cellAt returns the row and column of the cell drawn at (x, y), or -1, -1
+// if there is none.
+func (g *Game) cellAt(x, y int) (row, col int) {
+	i := g.fieldAt(x, y)
+	if i < 0 || i >= len(g.cells) {
+		return -1, -1
+	}
+	col = min((x-g.layout().margin)/g.cellWidth(), len(g.cells[i])-1)
+	return i, col
+}
+
+// drawCells draws row i of the grid where its equation field would be,
+// with a bar before the constants.
+func (g *Game) drawCells(screen *ebiten.Image, i int, th *Theme) {
+	if i >= len(g.cells) {
+		return
+	}
+	l := g.layout()
+	y, w := l.fieldY(i), g.cellWidth()
+	for j, cell := range g.cells[i] {
+		fill := th.Field
+		active := i == g.cellRow && j == g.cellCol
+		if i == g.errorField {
+			fill = th.ErrorField
+		} else if active {
+			fill = th.ActiveField
+		}
+		x := l.margin + j*w
+		drawBox(screen, x, y, w-g.px(4), l.fieldHeight, fill, th)
+		text.Draw(screen, cell, g.font, x+g.px(6), y+l.textInset, th.Text)
+		if active && g.caretTicks/30%2 == 0 {
+			cx := x + g.px(6) + font.MeasureString(g.font, cell).Ceil()
+			fillRect(screen, cx, y+g.px(8), g.px(2), l.fieldHeight-g.px(16), th.Text)
+		}
+	}
+	barX := l.margin + (len(g.cells[i])-1)*w - g.px(3)
+	fillRect(screen, barX, y, g.px(2), l.fieldHeight, th.Text)
+}
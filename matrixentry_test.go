@@ -0,0 +1,65 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRowEquation(t *testing.T) {
+	tests := []struct {
+		row  []string
+		want string
+	}{
+		{[]string{"2", "1", "-1", "8"}, "2x + y - z = 8"},
+		{[]string{"-1", "0", "1/2", "-3"}, "-x + 1/2z = -3"},
+		{[]string{"0", "-2.5", "1e-3"}, "-2.5y = 1e-3"},
+		{[]string{"0", "0", "5"}, "0x = 5"},
+		{[]string{"2", "", "1"}, ""},
+		{[]string{"2", "1", "x"}, ""},
+	}
+	for _, tt := range tests {
+		if got := rowEquation(tt.row); got != tt.want {
+			t.Errorf("rowEquation(%q) = %q, want %q", tt.row, got, tt.want)
+		}
+	}
+}
+
+func TestCellsFromEquations(t *testing.T) {
+	got := cellsFromEquations([]string{"2x + y = 3", "y = 0.5"})
+	want := [][]string{{"2", "1", "3"}, {"0", "1", "0.5"}}
+	for i := range want {
+		if !slices.Equal(got[i], want[i]) {
+			t.Errorf("row %d = %q, want %q", i+1, got[i], want[i])
+		}
+	}
+	if got := cellsFromEquations([]string{"x + z = 1", ""}); !slices.Equal(got[0], []string{"", "", ""}) || !slices.Equal(got[1], []string{"", "", ""}) {
+		t.Errorf("cells = %q, want empty rows for equations that don't fit", got)
+	}
+}
+
+func TestSolveMatrixMode(t *testing.T) {
+	g := &Game{equations: []string{"", ""}, errorField: -1, reopening: true}
+	g.toggleMatrixMode()
+	for _, cell := range []string{"2", "1", "3", "1", "-1"} {
+		g.editCell(cell)
+		g.nextCell()
+	}
+	g.solve()
+	if g.errorField != 1 || g.errorMsg != "Please fill in row 2, column 3" {
+		t.Errorf("errorField %d, errorMsg %q; want the empty cell in row 2", g.errorField, g.errorMsg)
+	}
+
+	g.editCell("0")
+	g.solve()
+	if g.solution != "x = 1, y = 1" {
+		t.Errorf("solution = %q, want x = 1, y = 1", g.solution)
+	}
+	if want := []string{"2x + y = 3", "x - y = 0"}; !slices.Equal(g.equations, want) {
+		t.Errorf("equations = %q, want %q", g.equations, want)
+	}
+
+	g.toggleMatrixMode()
+	if g.matrixMode || g.activeEquation != 1 {
+		t.Errorf("matrix mode %v, active equation %d after leaving it", g.matrixMode, g.activeEquation)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// renderSolution draws the whole solution, every step and the result, onto
+// an image as tall as the content, as if the screen were long enough to
+// show it without scrolling.
+func (g *Game) renderSolution() *image.RGBA {
+	currentStep, scroll, exitPrompt := g.currentStep, g.scroll, g.ShowExitPrompt
+	defer func() {
+		g.currentStep, g.scroll, g.ShowExitPrompt = currentStep, scroll, exitPrompt
+		g.redraw = true
+	}()
+	g.currentStep = len(g.steps)
+	g.scroll = 0
+	g.ShowExitPrompt = false
+	g.redraw = true
+
+	width := g.width
+	if width < 1 {
+		width = g.px(minWidth)
+	}
+	img := ebiten.NewImage(width, g.getContentHeight())
+	defer img.Deallocate()
+	g.Draw(img)
+
+	rgba := image.NewRGBA(img.Bounds())
+	img.ReadPixels(rgba.Pix)
+	return rgba
+}
+
+// writePNG encodes img as a PNG file at path.
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// exportPNG saves the full solution as a PNG image to the output directory.
+func (g *Game) exportPNG() {
+	if !g.solving && !g.solutionComplete {
+		g.errorMsg = "Solve a system before exporting it as PNG"
+		return
+	}
+	dir := g.solutionsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		g.saveStatus = "Could not export PNG: " + err.Error()
+		return
+	}
+	name := fmt.Sprintf("%s%s.png", solutionFilePrefix, time.Now().Format("2006-01-02_15-04-05"))
+	path := filepath.Join(dir, name)
+	if err := writePNG(path, g.renderSolution()); err != nil {
+		g.saveStatus = "Could not export PNG: " + err.Error()
+		return
+	}
+	g.saveStatus = "PNG written to " + path
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWritePNG(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(1, 1, color.RGBA{200, 50, 50, 255})
+	path := filepath.Join(t.TempDir(), "solution.png")
+	if err := writePNG(path, img); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("written file is not a PNG: %v", err)
+	}
+	if got.Bounds() != img.Bounds() {
+		t.Errorf("bounds = %v, want %v", got.Bounds(), img.Bounds())
+	}
+	if r, g, b, _ := got.At(1, 1).RGBA(); r>>8 != 200 || g>>8 != 50 || b>>8 != 50 {
+		t.Errorf("pixel (1, 1) = %v, want the color drawn", got.At(1, 1))
+	}
+}
+
+func TestExportPNGNeedsSolution(t *testing.T) {
+	t.Chdir(t.TempDir())
+	g := &Game{equations: make([]string, 3)}
+	g.exportPNG()
+	if g.errorMsg == "" {
+		t.Error("exporting before solving did not report an error")
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Error("exporting before solving created the output directory")
+	}
+}
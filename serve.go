@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/saedarm/go-gaussian/solver"
+)
+
+// Limits on a POST to /solve. The body cap alone doesn't bound the work,
+// as a megabyte holds hundreds of dense equations and elimination takes
+// cubic time in their number.
+const (
+	maxRequestBytes = 1 << 20
+	maxServeSize    = 100 // equations, and unknowns, in one system
+)
+
+// solveRequest is the body of a POST to /solve. Each entry is one equation,
+// or several separated by ';'.
+type solveRequest struct {
+	Equations []string `json:"equations"`
+}
+
+// newServeMux returns the handler for -serve: POST /solve solves the system
+// in the request with the headless solver, configured like the command
+// line, and responds with the same JSON the solutions are saved as.
+func newServeMux(debug bool, decimals int, tol solver.Tolerance, metricsPath string) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /solve", func(w http.ResponseWriter, r *http.Request) {
+		handleSolve(w, r, debug, decimals, tol, metricsPath)
+	})
+	return mux
+}
+
+func handleSolve(w http.ResponseWriter, r *http.Request, debug bool, decimals int, tol solver.Tolerance, metricsPath string) {
+	var req solveRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		writeJSONError(w, "invalid request body: "+err.Error())
+		return
+	}
+	equations := splitSystem(strings.Join(req.Equations, ";"))
+	if len(equations) == 0 {
+		writeJSONError(w, "no equations given")
+		return
+	}
+	if len(equations) > maxServeSize {
+		writeJSONError(w, fmt.Sprintf("%d equations given, at most %d are supported", len(equations), maxServeSize))
+		return
+	}
+
+	m, errs := solver.Parse(equations)
+	if m == nil {
+		for _, err := range errs {
+			if err != nil {
+				writeJSONError(w, "Error in "+err.Error())
+				return
+			}
+		}
+	}
+	if n := m.CoefficientColumns(); n > maxServeSize {
+		writeJSONError(w, fmt.Sprintf("%d unknowns used, at most %d are supported", n, maxServeSize))
+		return
+	}
+
+	m.SetDebug(debug)
+	m.SetDecimals(decimals)
+	m.SetTolerance(tol)
+	sol := solutionJSON{Equations: equations, Coefficients: m.Data()}
+	_, steps, err := m.Solve()
+	if m.Anomaly() != "" {
+		writeJSONError(w, "Debug: "+err.Error())
+		return
+	}
+	if metricsPath != "" {
+		if err := writeMetrics(metricsPath, eliminationMetrics(m, len(steps))); err != nil {
+			log.Printf("Error writing metrics: %v", err)
+		}
+	}
+	sol.Kind = m.Classify().String()
+	switch m.Classify() {
+	case solver.Unique:
+		sol.Solution = m.SolutionValues()
+		sol.Residuals = solver.Residuals(sol.Coefficients, m.SolutionVector())
+	case solver.Infinite:
+		sol.FreeVariables = m.CoefficientColumns() - m.CoefficientRank()
+		if m.IsHomogeneous() {
+			sol.NullSpace = m.NullSpaceBasis()
+		}
+	}
+	data, err := encodeSolution(sol, m, steps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// writeJSONError responds 400 with {"error": msg}.
+func writeJSONError(w http.ResponseWriter, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
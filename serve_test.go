@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/saedarm/go-gaussian/solver"
+)
+
+func postSolve(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/solve", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	newServeMux(false, solver.DefaultDecimals, solver.Tolerance{}, "").ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServeSolve(t *testing.T) {
+	rec := postSolve(t, `{"equations": ["2x + y - z = 8", "-3x - y + 2z = -11", "-2x + y + 2z = -3"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var sol solutionJSON
+	if err := json.Unmarshal(rec.Body.Bytes(), &sol); err != nil {
+		t.Fatal(err)
+	}
+	if sol.Kind != "unique" || len(sol.Steps) == 0 {
+		t.Errorf("kind = %q with %d steps", sol.Kind, len(sol.Steps))
+	}
+	for v, want := range map[string]float64{"x": 2, "y": 3, "z": -1} {
+		if got := sol.Solution[v]; math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", v, got, want)
+		}
+	}
+
+	rec = postSolve(t, `{"equations": ["x + y = 2; x - y = 0"]}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("inline system: status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	// More equations than there are fields in the window.
+	rec = postSolve(t, `{"equations": ["a = 1; b = 2; c = 3; d = 4; f = 5; g = 6; h = 7"]}`)
+	if err := json.Unmarshal(rec.Body.Bytes(), &sol); err != nil || sol.Kind != "unique" || sol.Solution["h"] != 7 {
+		t.Errorf("7 equations: status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	// The second coefficient is below the default epsilon, so the system
+	// only has a unique solution with the server's smaller one.
+	small := `{"equations": ["x + y = 2", "1e-11y = 1e-11"]}`
+	if err := json.Unmarshal(postSolve(t, small).Body.Bytes(), &sol); err != nil || sol.Kind != "infinite" {
+		t.Errorf("default epsilon: kind = %q (%v), want infinite", sol.Kind, err)
+	}
+	rec = httptest.NewRecorder()
+	newServeMux(false, solver.DefaultDecimals, solver.Tolerance{Epsilon: 1e-12}, "").ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/solve", strings.NewReader(small)))
+	if err := json.Unmarshal(rec.Body.Bytes(), &sol); err != nil || sol.Kind != "unique" {
+		t.Errorf("epsilon 1e-12: kind = %q (%v), want unique", sol.Kind, err)
+	}
+
+	rec = httptest.NewRecorder()
+	newServeMux(false, 3, solver.Tolerance{}, "").ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/solve", strings.NewReader(`{"equations": ["2x = 1"]}`)))
+	if err := json.Unmarshal(rec.Body.Bytes(), &sol); err != nil || !slices.Contains(sol.Steps, "L1 → 0.500L1") {
+		t.Errorf("3 decimals: steps %q (%v), want the scaling with 3 decimals", sol.Steps, err)
+	}
+
+	rec = httptest.NewRecorder()
+	newServeMux(true, solver.DefaultDecimals, solver.Tolerance{}, "").ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/solve", strings.NewReader(`{"equations": ["x + y = 1", "1e-13y = 1e-13"]}`)))
+	var resp map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); rec.Code != http.StatusBadRequest || err != nil || !strings.HasPrefix(resp["error"], "Debug: ") {
+		t.Errorf("-debug: status %d, body %s, want a 400 for the halted elimination", rec.Code, rec.Body)
+	}
+}
+
+func TestServeSolveErrors(t *testing.T) {
+	var unknowns []string
+	for i := 1; i <= maxServeSize+1; i++ {
+		unknowns = append(unknowns, fmt.Sprintf("x%d", i))
+	}
+	tests := []struct {
+		name, body, want string
+	}{
+		{"parse error", `{"equations": ["2x + y"]}`, "Error in equation 1"},
+		{"no equations", `{"equations": []}`, "no equations"},
+		{"bad JSON", `{"equations": `, "invalid request body"},
+		{"too large", `{"equations": ["` + strings.Repeat(" ", maxRequestBytes) + `x = 1"]}`, "request body too large"},
+		{"too many equations", `{"equations": ["` + strings.Repeat("x = 1;", maxServeSize+1) + `"]}`, "101 equations given, at most 100"},
+		{"too many unknowns", `{"equations": ["` + strings.Join(unknowns, " + ") + ` = 1"]}`, "101 unknowns used, at most 100"},
+	}
+	for _, tt := range tests {
+		rec := postSolve(t, tt.body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", tt.name, rec.Code)
+		}
+		var resp map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || !strings.Contains(resp["error"], tt.want) {
+			t.Errorf("%s: body %s, want an error containing %q", tt.name, rec.Body, tt.want)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/solve", nil)
+	rec := httptest.NewRecorder()
+	newServeMux(false, solver.DefaultDecimals, solver.Tolerance{}, "").ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /solve: status = %d, want 405", rec.Code)
+	}
+}
@@ -0,0 +1,65 @@
+package solver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ForwardElimination reduces m only to row echelon form: each pivot is
+// scaled to 1 and cleared below, and the entries above the pivots are left
+// for BackSubstitute.
+func (m *Matrix) ForwardElimination() []string {
+	m.echelon = true
+	defer func() { m.echelon = false }()
+	return m.GaussianElimination()
+}
+
+// BackSubstitute solves a matrix in row echelon form from
+// ForwardElimination with a unique solution, from the last row up, and
+// describes each substitution, e.g. "From L2: y = 5 - (2)(-1) = 7", after
+// a "Back substitution:" heading. Like the steps of GaussianElimination,
+// each one has an entry in the trace, the heading included. The matrix is
+// left in reduced row echelon form, so it reads like the result of
+// GaussianElimination.
+func (m *Matrix) BackSubstitute() []string {
+	n := m.CoefficientColumns()
+	last := m.cols - 1
+	steps := []string{"\nBack substitution:"}
+	m.trace = append(m.trace, Step{Column: -1, PivotRow: -1, After: m.snapshot()})
+	for i := n - 1; i >= 0; i-- {
+		var step strings.Builder
+		value := m.data[i][last]
+		fmt.Fprintf(&step, "From %s: %s = %s", m.labels.label(i), m.VariableName(i), FormatNumber(value, m.decimals))
+		substituted := false
+		for k := i + 1; k < n; k++ {
+			a := m.data[i][k]
+			if a == 0 {
+				continue
+			}
+			fmt.Fprintf(&step, " - (%s)(%s)", FormatNumber(a, m.decimals), FormatNumber(m.data[k][last], m.decimals))
+			value -= a * m.data[k][last]
+			m.data[i][k] = 0
+			substituted = true
+		}
+		m.data[i][last] = value
+		if substituted {
+			step.WriteString(" = " + FormatNumber(value, m.decimals))
+		}
+		steps = append(steps, step.String())
+		m.trace = append(m.trace, Step{Column: i, PivotRow: i, After: m.snapshot()})
+	}
+	return steps
+}
+
+// ReducedCopy returns a copy of m in reduced row echelon form, for the
+// results that need it after ForwardElimination.
+func (m *Matrix) ReducedCopy() *Matrix {
+	c := NewMatrix(m.rows, m.cols)
+	c.data = m.copyData()
+	c.coeffs = m.coeffs
+	c.decimals = m.decimals
+	c.tol = m.tol
+	c.names = m.names
+	c.GaussianElimination()
+	return c
+}
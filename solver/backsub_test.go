@@ -0,0 +1,55 @@
+package solver
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestForwardEliminationAndBackSubstitution(t *testing.T) {
+	m := NewMatrix(3, 4)
+	m.data = [][]float64{{1, 1, 1, 6}, {0, 2, 5, -4}, {2, 5, -1, 27}}
+	m.SetPivotStrategy(FirstNonZeroPivot)
+	steps := m.ForwardElimination()
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < i; j++ {
+			if m.data[i][j] != 0 {
+				t.Fatalf("not in row echelon form: %v", m.data)
+			}
+		}
+		if m.data[i][i] != 1 {
+			t.Errorf("pivot %d = %v, want 1", i, m.data[i][i])
+		}
+	}
+	if m.data[0][1] == 0 && m.data[0][2] == 0 {
+		t.Errorf("entries above the pivots were cleared: %v", m.data)
+	}
+	for _, step := range steps {
+		if strings.HasPrefix(step, "L1 +") {
+			t.Errorf("forward elimination changed a row above a pivot: %q", step)
+		}
+	}
+
+	sub := m.BackSubstitute()
+	if len(sub) != 4 || sub[0] != "\nBack substitution:" {
+		t.Fatalf("substitutions = %q, want a heading and one per row", sub)
+	}
+	if !strings.HasPrefix(sub[1], "From L3: z = ") || strings.Contains(sub[1], "(") {
+		t.Errorf("first substitution %q, want z read off the last row", sub[1])
+	}
+	if !strings.HasPrefix(sub[3], "From L1: x = 6 - (1)(") {
+		t.Errorf("last substitution = %q", sub[3])
+	}
+	if got, want := len(m.Trace()), len(steps)+len(sub); got != want {
+		t.Errorf("trace has %d entries for %d steps", got, want)
+	}
+	for i, want := range []float64{5, 3, -2} {
+		if math.Abs(m.data[i][3]-want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", VariableName(i), m.data[i][3], want)
+		}
+	}
+	if m.Classify() != Unique || m.data[0][1] != 0 {
+		t.Errorf("matrix not left reduced: %v", m.data)
+	}
+}
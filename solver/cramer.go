@@ -0,0 +1,68 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+)
+
+// squareDeterminant returns the determinant of a square matrix, found by
+// the elimination like every other determinant in the solver.
+func squareDeterminant(rows [][]float64, tol Tolerance) float64 {
+	m := NewMatrix(len(rows), len(rows)+1)
+	m.tol = tol
+	for i, row := range rows {
+		copy(m.data[i], row)
+	}
+	m.GaussianElimination()
+	return m.stats.Determinant
+}
+
+// Cramer solves the square system m by Cramer's rule: unknown i is
+// det(A_i) / det(A), where A_i is the coefficient matrix A with column i
+// replaced by the constants b. The steps show each determinant and ratio.
+// It returns ErrSingularMatrix if det(A) is zero, within the tolerance of
+// m, when the rule does not apply.
+func (m *Matrix) Cramer() ([]float64, []string, error) {
+	n := m.CoefficientColumns()
+	if m.rows != n {
+		return nil, nil, fmt.Errorf("Cramer's rule needs a square system, got %d equations in %d unknowns", m.rows, n)
+	}
+	a := make([][]float64, n)
+	for i := range a {
+		a[i] = m.data[i][:n]
+	}
+	det := squareDeterminant(a, m.tol)
+	steps := []string{"Cramer's rule: each unknown is det(A_i) / det(A)", "det(A) = " + FormatNumber(det, m.decimals)}
+	if m.tol.isZero(det) {
+		steps = append(steps, "det(A) = 0, so Cramer's rule does not apply")
+		return nil, steps, ErrSingularMatrix
+	}
+
+	values := make([]float64, n)
+	for col := range values {
+		ai := make([][]float64, n)
+		for i := range ai {
+			ai[i] = append([]float64(nil), a[i]...)
+			ai[i][col] = m.data[i][n]
+		}
+		d := squareDeterminant(ai, m.tol)
+		values[col] = d / det
+		name := m.VariableName(col)
+		steps = append(steps,
+			fmt.Sprintf("det(A_%s) = %s (column %s replaced by b)", name, FormatNumber(d, m.decimals), name),
+			fmt.Sprintf("%s = det(A_%s) / det(A) = %s / %s = %s", name, name,
+				FormatNumber(d, m.decimals), FormatNumber(det, m.decimals), FormatNumber(values[col], m.decimals)))
+	}
+	return values, steps, nil
+}
+
+// CramerSteps lists the Cramer's rule computation for the step list,
+// explaining why it does not apply when it doesn't, and returns the values
+// of Cramer and its error.
+func CramerSteps(m *Matrix) ([]float64, []string, error) {
+	values, steps, err := m.Cramer()
+	if err != nil && !errors.Is(err, ErrSingularMatrix) {
+		return nil, []string{"Cramer's rule does not apply: " + err.Error()}, err
+	}
+	return values, steps, err
+}
@@ -0,0 +1,45 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCramer(t *testing.T) {
+	m := NewMatrix(3, 4)
+	m.data = [][]float64{{2, 1, -1, 8}, {-3, -1, 2, -11}, {-2, 1, 2, -3}}
+	values, steps, err := m.Cramer()
+	if err != nil {
+		t.Fatalf("Cramer: %v", err)
+	}
+	for i, want := range []float64{2, 3, -1} {
+		if math.Abs(values[i]-want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", VariableName(i), values[i], want)
+		}
+	}
+	if steps[1] != "det(A) = -1" || steps[2] != "det(A_x) = -2 (column x replaced by b)" || steps[3] != "x = det(A_x) / det(A) = -2 / -1 = 2" {
+		t.Errorf("steps = %q", steps)
+	}
+
+	singular := NewMatrix(2, 3)
+	singular.data = [][]float64{{1, 2, 3}, {2, 4, 6}}
+	if _, steps, err := singular.Cramer(); !errors.Is(err, ErrSingularMatrix) || steps[len(steps)-1] != "det(A) = 0, so Cramer's rule does not apply" {
+		t.Errorf("singular system: err = %v, steps %q", err, steps)
+	}
+
+	if _, got, err := CramerSteps(NewMatrix(2, 4)); len(got) != 1 || err == nil {
+		t.Errorf("non-square system: steps %q, err %v, want a single explanation", got, err)
+	}
+
+	// det(A) = 1e-12 is zero by default but not for a smaller epsilon.
+	tiny := NewMatrix(2, 3)
+	tiny.data = [][]float64{{1e-6, 0, 1e-6}, {0, 1e-6, 2e-6}}
+	if _, _, err := CramerSteps(tiny); !errors.Is(err, ErrSingularMatrix) {
+		t.Errorf("det 1e-12 with the default epsilon: err = %v", err)
+	}
+	tiny.SetTolerance(Tolerance{Epsilon: 1e-15})
+	if values, _, err := CramerSteps(tiny); err != nil || math.Abs(values[1]-2) > 1e-9 {
+		t.Errorf("det 1e-12 with epsilon 1e-15 = %v, %v, want x = 1, y = 2", values, err)
+	}
+}
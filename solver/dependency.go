@@ -0,0 +1,98 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// EquationNotes describes the equations of the augmented matrix m, before
+// elimination, that don't pull their weight: those without unknowns, see
+// emptyEquations, and those that are multiples of another, see
+// dependentEquations.
+func EquationNotes(m *Matrix) []string {
+	return append(emptyEquations(m), dependentEquations(m)...)
+}
+
+// emptyEquations describes the equations of the augmented matrix m whose
+// unknowns all cancel or are missing, like 0 = 0 or x - x = 3. The first
+// kind carries no information, so the system is an equation short; the
+// second is never true, so the system has no solution.
+func emptyEquations(m *Matrix) []string {
+	n := m.CoefficientColumns()
+	if n != m.cols-1 {
+		return nil
+	}
+	var notes []string
+	for i, row := range m.data {
+		if slices.ContainsFunc(row[:n], func(v float64) bool { return !m.tol.isZero(v) }) {
+			continue
+		}
+		if m.tol.isZero(row[n]) {
+			notes = append(notes, fmt.Sprintf("Equation %d reduces to 0 = 0 and carries no information: fix or remove it", i+1))
+		} else {
+			notes = append(notes, fmt.Sprintf("Equation %d reduces to 0 = %s, which is never true: fix or remove it", i+1, FormatValue(row[n], m.decimals)))
+		}
+	}
+	return notes
+}
+
+// dependentEquations compares every pair of equations of the augmented
+// matrix m before elimination and describes the pairs whose left sides
+// are scalar multiples of each other: the same equation written twice,
+// which leaves the system short of an equation, or two equations that
+// contradict each other, which leave it without a solution. Equations
+// with no unknowns are left to emptyEquations.
+func dependentEquations(m *Matrix) []string {
+	n := m.CoefficientColumns()
+	if n != m.cols-1 {
+		return nil
+	}
+	var notes []string
+	for i := 0; i < m.rows; i++ {
+		for j := i + 1; j < m.rows; j++ {
+			ratio, ok := rowRatio(m.data[i][:n], m.data[j][:n])
+			if !ok {
+				continue
+			}
+			scaled := fmt.Sprintf("%s × equation %d", FormatValue(ratio, m.decimals), i+1)
+			if ratio == 1 {
+				scaled = fmt.Sprintf("equation %d", i+1)
+			}
+			if nearlyEqual(m.data[j][n], ratio*m.data[i][n]) {
+				notes = append(notes, fmt.Sprintf("Equations %d and %d are dependent: equation %d is %s", i+1, j+1, j+1, scaled))
+			} else {
+				notes = append(notes, fmt.Sprintf("Equations %d and %d conflict: the left side of equation %d is %s but the right side is not", i+1, j+1, j+1, scaled))
+			}
+		}
+	}
+	return notes
+}
+
+// rowRatio returns r such that b = r·a, if there is one and a is not all
+// zeros.
+func rowRatio(a, b []float64) (float64, bool) {
+	k := -1
+	for i, v := range a {
+		if v != 0 {
+			k = i
+			break
+		}
+	}
+	if k < 0 || b[k] == 0 {
+		return 0, false
+	}
+	r := b[k] / a[k]
+	for i := range a {
+		if !nearlyEqual(b[i], r*a[i]) {
+			return 0, false
+		}
+	}
+	return r, true
+}
+
+// nearlyEqual compares parsed coefficients, allowing for the rounding of
+// decimals such as 0.1 and of the ratio between two rows.
+func nearlyEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
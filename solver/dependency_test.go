@@ -0,0 +1,53 @@
+package solver
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDependentEquations(t *testing.T) {
+	tests := []struct {
+		equations []string
+		want      []string
+	}{
+		{[]string{"2x + y = 3", "x - y = 0"}, nil},
+		{[]string{"x + y = 3", "x + y = 3"}, []string{"Equations 1 and 2 are dependent: equation 2 is equation 1"}},
+		{[]string{"x + y - z = 1", "2x - y = 0", "2x + 2y - 2z = 2"}, []string{"Equations 1 and 3 are dependent: equation 3 is 2 × equation 1"}},
+		{[]string{"2x + 4y = 1", "x + 2y = 3"}, []string{"Equations 1 and 2 conflict: the left side of equation 2 is 1/2 × equation 1 but the right side is not"}},
+		{[]string{"0.1x + 0.2y = 0.3", "x + 2y = 3"}, []string{"Equations 1 and 2 are dependent: equation 2 is 10 × equation 1"}},
+		{[]string{"x + y = 1", "x = 2"}, nil},
+	}
+	for _, tt := range tests {
+		m, errs := Parse(tt.equations)
+		if m == nil {
+			t.Fatalf("Parse(%q): %v", tt.equations, errors.Join(errs...))
+		}
+		m.decimals = DefaultDecimals
+		if got := dependentEquations(m); !slices.Equal(got, tt.want) {
+			t.Errorf("%q: %q, want %q", tt.equations, got, tt.want)
+		}
+	}
+}
+
+func TestEmptyEquations(t *testing.T) {
+	tests := []struct {
+		equations []string
+		want      []string
+	}{
+		{[]string{"x + y = 3", "x - y = 1"}, nil},
+		{[]string{"0 = 0", "x + y = 2"}, []string{"Equation 1 reduces to 0 = 0 and carries no information: fix or remove it"}},
+		{[]string{"x + y = 2", "x - x = 3"}, []string{"Equation 2 reduces to 0 = 3, which is never true: fix or remove it"}},
+		{[]string{"x + y = 2", "0.3y - 0.1y - 0.2y = 0"}, []string{"Equation 2 reduces to 0 = 0 and carries no information: fix or remove it"}},
+	}
+	for _, tt := range tests {
+		m, errs := Parse(tt.equations)
+		if m == nil {
+			t.Fatalf("Parse(%q): %v", tt.equations, errors.Join(errs...))
+		}
+		m.decimals = DefaultDecimals
+		if got := emptyEquations(m); !slices.Equal(got, tt.want) {
+			t.Errorf("%q: %q, want %q", tt.equations, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// PivotStrategy chooses the pivot row for column col from the rows
+// startRow..rows-1 of m, returning -1 when none of them can be used. The
+// chosen entry must be non-zero; invalid choices fall back to
+// FirstNonZeroPivot.
+type PivotStrategy func(m *Matrix, col, startRow int) int
+
+// FirstNonZeroPivot picks the first row with a non-zero entry in col.
+func FirstNonZeroPivot(m *Matrix, col, startRow int) int {
+	for i := startRow; i < m.rows; i++ {
+		if !m.tol.isZero(m.data[i][col]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// PartialPivot picks the row with the largest absolute entry in col, the
+// first of them on a tie. Dividing by the largest available pivot keeps
+// rounding errors small. It is the default strategy.
+func PartialPivot(m *Matrix, col, startRow int) int {
+	best := -1
+	for i := startRow; i < m.rows; i++ {
+		v := math.Abs(m.data[i][col])
+		if !m.tol.isZero(v) && (best < 0 || v > math.Abs(m.data[best][col])) {
+			best = i
+		}
+	}
+	return best
+}
+
+// choosePivot applies the matrix's pivot strategy to col, guarding against
+// strategies that return an unusable row.
+func (m *Matrix) choosePivot(col, startRow int) int {
+	strategy := m.pivot
+	if strategy == nil {
+		strategy = PartialPivot
+	}
+	i := strategy(m, col, startRow)
+	if i == -1 || i >= startRow && i < m.rows && !m.tol.isZero(m.data[i][col]) {
+		return i
+	}
+	return FirstNonZeroPivot(m, col, startRow)
+}
+
+func (m *Matrix) GaussianElimination() []string {
+	steps := []string{}
+	lead := 0
+
+	m.trace = nil
+	m.anomaly = ""
+	pivotRow := -1
+	addStep := func(column int, format string, args ...any) {
+		steps = append(steps, fmt.Sprintf(format, args...))
+		m.trace = append(m.trace, Step{Column: column, PivotRow: pivotRow, After: m.snapshot()})
+		if m.debug && m.anomaly == "" {
+			if row, col, ok := m.findNonFinite(); ok {
+				m.anomaly = fmt.Sprintf("non-finite value %v at row %d, column %d after %q",
+					m.data[row][col], row+1, col+1, steps[len(steps)-1])
+			}
+		}
+	}
+
+	isZero := m.tol.isZero
+	round := func(x float64, precision int) float64 {
+		multiplier := math.Pow(10, float64(precision))
+		return math.Round(x*multiplier) / multiplier
+	}
+
+	m.singular = nil
+	m.stats = Stats{}
+
+	// With a single right-hand side a pivot may land in it, which is how an
+	// inconsistent row shows up. With several, pivoting there would mix the
+	// right-hand sides, so elimination stops at the bar.
+	n := m.CoefficientColumns()
+	limit := m.cols
+	if n < m.cols-1 {
+		limit = n
+	}
+
+	// The determinant of the coefficient block is the product of the
+	// pivots, negated once per row swap. It is only meaningful for a
+	// square coefficient block and is zero if any column lacks a pivot.
+	det := 1.0
+	coefficientPivots := 0
+	start := time.Now()
+	defer func() {
+		m.stats.Elapsed = time.Since(start)
+		if m.rows == n && coefficientPivots == m.rows {
+			m.stats.Determinant = det
+		}
+	}()
+
+	addStep(-1, "Starting Gaussian Elimination...")
+	if m.anomaly != "" {
+		return steps
+	}
+
+	for r := 0; r < m.rows; r++ {
+		if lead >= limit {
+			return steps
+		}
+
+		pivotRow = -1
+		i := m.choosePivot(lead, r)
+		for i < 0 {
+			// Only coefficient columns can make the system singular;
+			// running out of pivots in the constant column just means
+			// the remaining rows are all zero.
+			if lead < n {
+				addStep(lead, "Singular at step %d: no non-zero pivot in column %d (%s)",
+					r+1, lead+1, m.VariableName(lead))
+				if m.singular == nil {
+					m.singular = &Singularity{Step: r + 1, Column: lead}
+				}
+				// A column whose entries cancelled exactly is a free
+				// variable; one whose entries are only below epsilon may
+				// have lost its pivot to the tolerance.
+				if m.debug {
+					if row, ok := m.lostPivot(lead, r); ok {
+						m.anomaly = fmt.Sprintf("pivot lost to tolerance in column %d at step %d: %v at row %d is below epsilon",
+							lead+1, r+1, m.data[row][lead], row+1)
+						return steps
+					}
+				}
+			}
+			lead++
+			if lead == limit {
+				return steps
+			}
+			i = m.choosePivot(lead, r)
+		}
+
+		pivotRow = r
+		if i != r {
+			if err := m.SwapRows(i, r); err != nil {
+				m.anomaly = err.Error()
+				return steps
+			}
+			m.stats.Swaps++
+			det = -det
+			addStep(lead, "%s ↔ %s", m.labels.label(i), m.labels.label(r))
+		}
+
+		if lead < n {
+			det *= m.data[r][lead]
+			p := math.Abs(m.data[r][lead])
+			if coefficientPivots == 0 || p < m.stats.MinPivot {
+				m.stats.MinPivot = p
+			}
+			m.stats.MaxPivot = math.Max(m.stats.MaxPivot, p)
+			coefficientPivots++
+		}
+
+		if !isZero(m.data[r][lead] - 1) {
+			// The scalar is not rounded: with partial pivoting it is
+			// often a repeating fraction such as 1/3, and rounding it
+			// would leave the pivot just short of 1.
+			scalar := 1.0 / m.data[r][lead]
+			m.MultiplyRow(r, scalar)
+			m.stats.Scalings++
+			addStep(lead, "%s → %s%s", m.labels.label(r), FormatNumber(scalar, m.decimals), m.labels.label(r))
+			if m.anomaly != "" {
+				return steps
+			}
+		}
+
+		// Rows above the pivot are only cleared when reducing fully.
+		first := 0
+		if m.echelon {
+			first = r + 1
+		}
+		for i := first; i < m.rows; i++ {
+			if i != r {
+				scalar := -m.data[i][lead]
+				if !isZero(scalar) {
+					m.AddMultipleOfRow(i, r, scalar)
+					m.roundRow(i)
+					m.stats.RowAdds++
+					if round(scalar, 5) == -1 {
+						addStep(lead, "%s + %s → %s", m.labels.label(i), m.labels.label(r), m.labels.label(i))
+					} else {
+						addStep(lead, "%s + %s%s → %s", m.labels.label(i), FormatNumber(scalar, m.decimals), m.labels.label(r), m.labels.label(i))
+					}
+					if m.anomaly != "" {
+						return steps
+					}
+				}
+			}
+		}
+		// The pivot row is rounded last, as the other rows were cleared
+		// with its exact values.
+		m.roundRow(r)
+
+		lead++
+	}
+
+	return steps
+}
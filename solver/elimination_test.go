@@ -0,0 +1,422 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEliminationStats(t *testing.T) {
+	m := NewMatrix(3, 4)
+	m.data = [][]float64{{0, 1, 1, 5}, {1, 0, 1, 4}, {1, 1, 0, 3}}
+	m.GaussianElimination()
+
+	if m.stats.Swaps != 1 {
+		t.Errorf("swaps = %d, want 1", m.stats.Swaps)
+	}
+	if m.stats.RowAdds == 0 {
+		t.Error("rowAdds = 0, want row additions to be counted")
+	}
+	if math.Abs(m.stats.Determinant-2) > 1e-6 {
+		t.Errorf("determinant = %v, want 2", m.stats.Determinant)
+	}
+
+	singular := NewMatrix(3, 4)
+	singular.data = [][]float64{{1, 2, 3, 1}, {2, 4, 6, 2}, {1, 0, 1, 0}}
+	singular.GaussianElimination()
+	if singular.stats.Determinant != 0 {
+		t.Errorf("singular determinant = %v, want 0", singular.stats.Determinant)
+	}
+}
+
+func TestEliminationStatsSummary(t *testing.T) {
+	s := Stats{Swaps: 1, Scalings: 3, RowAdds: 6, Elapsed: 12500 * time.Nanosecond}
+	if got, want := s.Summary(), "Row operations: 1 swap, 3 scalings, 6 additions in 12.5µs"; got != want {
+		t.Errorf("Summary = %q, want %q", got, want)
+	}
+}
+
+func TestEliminationTraceColumns(t *testing.T) {
+	m := NewMatrix(3, 4)
+	m.data = [][]float64{{0, 1, 1, 5}, {1, 0, 1, 4}, {1, 1, 0, 3}}
+	steps := m.GaussianElimination()
+
+	if len(m.trace) != len(steps) {
+		t.Fatalf("trace has %d entries for %d steps", len(m.trace), len(steps))
+	}
+	if m.trace[0].Column != -1 {
+		t.Errorf("first step column = %d, want -1", m.trace[0].Column)
+	}
+	last := -1
+	for i, info := range m.trace[1:] {
+		if info.Column < last {
+			t.Errorf("step %q moves back from column %d to %d", steps[i+1], last, info.Column)
+		}
+		last = info.Column
+	}
+	if last != 2 {
+		t.Errorf("last eliminated column = %d, want 2 (z)", last)
+	}
+}
+
+func TestEliminationTracePivots(t *testing.T) {
+	m := NewMatrix(3, 4)
+	m.data = [][]float64{{0, 1, 1, 5}, {1, 0, 1, 4}, {1, 1, 0, 3}}
+	m.SetSnapshots(true)
+	steps := m.GaussianElimination()
+
+	if !strings.Contains(steps[1], "↔") {
+		t.Fatalf("step 1 = %q, want a swap", steps[1])
+	}
+	if info := m.trace[1]; info.PivotRow != 0 || info.Column != 0 || info.After[0][0] != 1 {
+		t.Errorf("swap step: pivot (%d, %d), first row %v", info.PivotRow, info.Column, info.After[0])
+	}
+	if got := m.trace[len(m.trace)-1].After; !reflect.DeepEqual(got, m.data) {
+		t.Errorf("last snapshot %v, want the final matrix %v", got, m.data)
+	}
+	if m.trace[1].After[0][0] == m.trace[0].After[0][0] {
+		t.Error("snapshots share rows with the matrix")
+	}
+
+	m.data = [][]float64{{0, 1, 1, 5}, {1, 0, 1, 4}, {1, 1, 0, 3}}
+	m.SetSnapshots(false)
+	m.GaussianElimination()
+	for i, info := range m.trace {
+		if info.After != nil {
+			t.Errorf("trace[%d] has a snapshot without SetSnapshots", i)
+		}
+	}
+	if info := m.trace[1]; info.PivotRow != 0 || info.Column != 0 {
+		t.Errorf("swap step without snapshots: pivot (%d, %d)", info.PivotRow, info.Column)
+	}
+}
+
+func TestPivotStrategy(t *testing.T) {
+	rows := [][]float64{{1, 1, 1, 6}, {2, -1, 1, 3}, {4, 1, -1, 3}}
+
+	largest := func(m *Matrix, col, startRow int) int {
+		best := -1
+		for i := startRow; i < m.Rows(); i++ {
+			if m.At(i, col) != 0 && (best < 0 || math.Abs(m.At(i, col)) > math.Abs(m.At(best, col))) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	m := NewMatrix(3, 4)
+	for i := range rows {
+		copy(m.data[i], rows[i])
+	}
+	m.SetPivotStrategy(largest)
+	steps := m.GaussianElimination()
+
+	if steps[1] != "L3 ↔ L1" {
+		t.Errorf("first operation = %q, want the largest pivot swapped up (L3 ↔ L1)", steps[1])
+	}
+	want := []float64{1, 2, 3}
+	for i, v := range want {
+		if math.Abs(m.data[i][3]-v) > 1e-4 {
+			t.Errorf("solution[%d] = %v, want %v", i, m.data[i][3], v)
+		}
+	}
+
+	naive := NewMatrix(3, 4)
+	for i := range rows {
+		copy(naive.data[i], rows[i])
+	}
+	naive.SetPivotStrategy(func(m *Matrix, col, startRow int) int { return m.Rows() + 5 })
+	for _, step := range naive.GaussianElimination() {
+		if strings.Contains(step, "↔") {
+			t.Errorf("invalid strategy choice should fall back to the first non-zero row, got swap %q", step)
+		}
+	}
+}
+
+func TestGaussianEliminationSolves(t *testing.T) {
+	isScale := func(step string) bool { return strings.Contains(step, "→") && !strings.Contains(step, "+") }
+	isSwap := func(step string) bool { return strings.Contains(step, "↔") }
+
+	tests := []struct {
+		name string
+		rows [][]float64
+		want []float64 // the last column of the reduced matrix
+		step func(string) bool
+	}{
+		{"unique solution", [][]float64{{2, 1, -1, 8}, {-3, -1, 2, -11}, {-2, 1, 2, -3}}, []float64{2, 3, -1}, isScale},
+		{"zero pivot", [][]float64{{0, 1, 1, 5}, {1, 0, 1, 4}, {1, 1, 0, 3}}, []float64{1, 2, 3}, isSwap},
+	}
+	for _, tt := range tests {
+		m := NewMatrix(3, 4)
+		m.data = tt.rows
+		steps := m.GaussianElimination()
+		for i, v := range tt.want {
+			for j := 0; j < 3; j++ {
+				id := 0.0
+				if i == j {
+					id = 1
+				}
+				if math.Abs(m.data[i][j]-id) > 1e-9 {
+					t.Errorf("%s: row %d is %v, want an identity row", tt.name, i, m.data[i])
+					break
+				}
+			}
+			if math.Abs(m.data[i][3]-v) > 1e-9 {
+				t.Errorf("%s: %s = %v, want %v", tt.name, VariableName(i), m.data[i][3], v)
+			}
+		}
+		if !slices.ContainsFunc(steps, tt.step) {
+			t.Errorf("%s: steps %q lack the expected operation", tt.name, steps)
+		}
+	}
+
+	m := NewMatrix(3, 4)
+	m.data = [][]float64{{1, 2, 3, 1}, {2, 4, 6, 2}, {1, 0, 1, 0}}
+	steps := m.GaussianElimination()
+	if m.Classify() != Infinite {
+		t.Errorf("singular system classified as %v, final matrix %v", m.Classify(), m.data)
+	}
+	for j, v := range m.data[2] {
+		if math.Abs(v) > 1e-9 {
+			t.Errorf("singular system: last row %v, want zeros (entry %d)", m.data[2], j)
+			break
+		}
+	}
+	if !slices.ContainsFunc(steps, func(s string) bool { return strings.HasPrefix(s, "Singular") }) {
+		t.Errorf("singular system: steps %q do not report the missing pivot", steps)
+	}
+}
+
+// TestGaussianEliminationStepGolden locks the exact step wording students
+// see. Note the historical quirk: a multiplier of -1 is printed as
+// "Li + Lj", without the sign or coefficient.
+func TestGaussianEliminationStepGolden(t *testing.T) {
+	tests := []struct {
+		name  string
+		pivot PivotStrategy
+		rows  [][]float64
+		steps []string
+	}{
+		{
+			name:  "textbook system",
+			pivot: FirstNonZeroPivot,
+			rows:  [][]float64{{2, 1, -1, 8}, {-3, -1, 2, -11}, {-2, 1, 2, -3}},
+			steps: []string{
+				"Starting Gaussian Elimination...",
+				"L1 → 0.50L1",
+				"L2 + 3L1 → L2",
+				"L3 + 2L1 → L3",
+				"L2 → 2L2",
+				"L1 + -0.50L2 → L1",
+				"L3 + -2L2 → L3",
+				"L3 → -1L3",
+				"L1 + 1L3 → L1",
+				"L2 + L3 → L2",
+			},
+		},
+		{
+			name:  "zero leading pivot",
+			pivot: FirstNonZeroPivot,
+			rows:  [][]float64{{0, 1, 1, 5}, {1, 0, 1, 4}, {1, 1, 0, 3}},
+			steps: []string{
+				"Starting Gaussian Elimination...",
+				"L2 ↔ L1",
+				"L3 + L1 → L3",
+				"L3 + L2 → L3",
+				"L3 → -0.50L3",
+				"L1 + L3 → L1",
+				"L2 + L3 → L2",
+			},
+		},
+		{
+			name:  "singular system",
+			pivot: FirstNonZeroPivot,
+			rows:  [][]float64{{1, 2, 3, 1}, {2, 4, 6, 2}, {1, 0, 1, 0}},
+			steps: []string{
+				"Starting Gaussian Elimination...",
+				"L2 + -2L1 → L2",
+				"L3 + L1 → L3",
+				"L3 ↔ L2",
+				"L2 → -0.50L2",
+				"L1 + -2L2 → L1",
+				"Singular at step 3: no non-zero pivot in column 3 (z)",
+			},
+		},
+		{
+			name: "textbook system, partial pivoting",
+			rows: [][]float64{{2, 1, -1, 8}, {-3, -1, 2, -11}, {-2, 1, 2, -3}},
+			steps: []string{
+				"Starting Gaussian Elimination...",
+				"L2 ↔ L1",
+				"L1 → -0.33L1",
+				"L2 + -2L1 → L2",
+				"L3 + 2L1 → L3",
+				"L3 ↔ L2",
+				"L2 → 0.60L2",
+				"L1 + -0.33L2 → L1",
+				"L3 + -0.33L2 → L3",
+				"L3 → 5L3",
+				"L1 + 0.80L3 → L1",
+				"L2 + -0.40L3 → L2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		m := NewMatrix(3, 4)
+		m.data = tt.rows
+		m.SetPivotStrategy(tt.pivot)
+		got := m.GaussianElimination()
+		if strings.Join(got, "\n") != strings.Join(tt.steps, "\n") {
+			t.Errorf("%s: steps =\n%s\nwant\n%s", tt.name, strings.Join(got, "\n"), strings.Join(tt.steps, "\n"))
+		}
+	}
+}
+
+func TestZeroIndexedRowLabels(t *testing.T) {
+	m := NewMatrix(3, 4)
+	m.data = [][]float64{{0, 1, 1, 5}, {1, 0, 1, 4}, {1, 1, 0, 3}}
+	m.SetRowLabels("R", 0)
+	steps := m.GaussianElimination()
+
+	want := []string{"R1 ↔ R0", "R2 + R0 → R2", "R2 + R1 → R2", "R2 → -0.50R2"}
+	for i, w := range want {
+		if steps[i+1] != w {
+			t.Errorf("steps[%d] = %q, want %q", i+1, steps[i+1], w)
+		}
+	}
+}
+
+func TestDebugModeHalts(t *testing.T) {
+	m := NewMatrix(3, 4)
+	m.data = [][]float64{{1, 2, 3, 1}, {2, 4, 6, 2}, {1, 0, 1, 0}}
+	m.debug = true
+	m.GaussianElimination()
+	if m.anomaly != "" || m.Classify() != Infinite {
+		t.Errorf("dependent system: anomaly %q, kind %v; want it solved with a free column", m.anomaly, m.Classify())
+	}
+
+	lost := NewMatrix(2, 3)
+	lost.data = [][]float64{{1, 1, 2}, {0, 1e-12, 1e-12}}
+	lost.debug = true
+	steps := lost.GaussianElimination()
+	if !strings.Contains(lost.anomaly, "pivot lost to tolerance in column 2 at step 2: 1e-12 at row 2") {
+		t.Errorf("anomaly = %q, want the pivot of column 2 lost to the tolerance", lost.anomaly)
+	}
+	if last := steps[len(steps)-1]; !strings.HasPrefix(last, "Singular") {
+		t.Errorf("last step = %q, want elimination to stop at the singular column", last)
+	}
+
+	inf := NewMatrix(3, 4)
+	inf.data = [][]float64{{1, 0, 0, 1}, {0, 1, 0, math.Inf(1)}, {0, 0, 1, 1}}
+	inf.debug = true
+	inf.GaussianElimination()
+	if !strings.Contains(inf.anomaly, "non-finite value +Inf at row 2, column 4") {
+		t.Errorf("anomaly = %q, want a non-finite value", inf.anomaly)
+	}
+}
+
+func TestToleranceInElimination(t *testing.T) {
+	// The second equation's coefficient is below the default epsilon. Its
+	// row is left alone by the first pass, so it isn't rounded.
+	small := []string{"x + y = 2", "1e-11y = 1e-11"}
+	// Here clearing x leaves 1e-11 in the second row, which the default
+	// rounding clears.
+	cancelled := []string{"x + y = 2", "x + 1.00000000001y = 2.00000000001"}
+	tests := []struct {
+		equations []string
+		tol       Tolerance
+		want      Kind
+	}{
+		{small, Tolerance{}, Infinite},
+		{small, Tolerance{Epsilon: 1e-12}, Unique},
+		{small, Tolerance{Epsilon: 1e-11, RoundDigits: -1}, Unique},
+		{small, Tolerance{Epsilon: 1.1e-11, RoundDigits: -1}, Infinite},
+		{cancelled, Tolerance{Epsilon: 1e-12}, Infinite},
+		{cancelled, Tolerance{Epsilon: 1e-12, RoundDigits: -1}, Unique},
+	}
+	for _, tt := range tests {
+		m, errs := Parse(tt.equations)
+		if m == nil {
+			t.Fatalf("Parse: %v", errors.Join(errs...))
+		}
+		m.tol = tt.tol
+		m.GaussianElimination()
+		if got := m.Classify(); got != tt.want {
+			t.Errorf("%q %+v: classified as %v, want %v", tt.equations, tt.tol, got, tt.want)
+		}
+	}
+}
+
+func TestPartialPivotingTinyPivot(t *testing.T) {
+	// With the tiny pivot taken first, x comes out of a cancellation and is
+	// off by about 2e-8.
+	const p = 7e-9
+	m := NewMatrix(2, 3)
+	m.data = [][]float64{{p, 1, 1}, {1, 1, 2}}
+	m.GaussianElimination()
+	want := []float64{1 / (1 - p), (1 - 2*p) / (1 - p)}
+	for i, name := range []string{"x", "y"} {
+		if math.Abs(m.data[i][2]-want[i]) > 1e-10 {
+			t.Errorf("%s = %.12f, want %.12f", name, m.data[i][2], want[i])
+		}
+	}
+}
+
+func TestDegenerateMatricesDoNotPanic(t *testing.T) {
+	tests := []struct {
+		name string
+		data [][]float64
+		want error
+	}{
+		{"all zero", [][]float64{{0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}, ErrInfiniteSolutions},
+		{"zero coefficients", [][]float64{{0, 0, 1}, {0, 0, 2}}, ErrNoSolution},
+		{"trailing zero columns", [][]float64{{1, 0, 0, 2}, {2, 0, 0, 4}}, ErrInfiniteSolutions},
+		{"constants only", [][]float64{{3}, {0}}, ErrNoSolution},
+	}
+	for _, tt := range tests {
+		m := NewMatrix(len(tt.data), len(tt.data[0]))
+		m.data = tt.data
+		if _, _, err := m.Solve(); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+// wellConditioned returns an n x (n+1) augmented matrix with random
+// entries in [-1, 1) and a diagonal large enough to dominate its row, so
+// the elimination never meets a tiny pivot.
+func wellConditioned(n int, r *rand.Rand) [][]float64 {
+	data := make([][]float64, n)
+	for i := range data {
+		data[i] = make([]float64, n+1)
+		for j := range data[i] {
+			data[i][j] = 2*r.Float64() - 1
+		}
+		data[i][i] = float64(n) + 1
+	}
+	return data
+}
+
+func BenchmarkGaussianElimination(b *testing.B) {
+	for _, n := range []int{3, 6, 10, 25, 50} {
+		b.Run(fmt.Sprintf("%dx%d", n, n+1), func(b *testing.B) {
+			input := wellConditioned(n, rand.New(rand.NewPCG(1, uint64(n))))
+			m := NewMatrix(n, n+1)
+			for b.Loop() {
+				// Copying into the rows of m keeps the input's
+				// allocation out of the measurement.
+				for i, row := range input {
+					copy(m.data[i], row)
+				}
+				m.GaussianElimination()
+			}
+		})
+	}
+}
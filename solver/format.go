@@ -0,0 +1,51 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// FormatNumber formats v with the given number of decimals, leaving them
+// out when v rounds to a whole number.
+func FormatNumber(v float64, decimals int) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	r := math.Round(v)
+	if r == 0 {
+		r = 0 // drop the sign of -0
+	}
+	if strconv.FormatFloat(r, 'f', decimals, 64) == s || s == "-"+strconv.FormatFloat(0, 'f', decimals, 64) {
+		return strconv.FormatFloat(r, 'f', 0, 64)
+	}
+	return s
+}
+
+// maxFractionDenominator is the largest denominator FormatValue writes a
+// fraction with.
+const maxFractionDenominator = 100
+
+// FormatValue formats a solution value as a fraction like 1/3 when it is
+// within rounding error of one with a small denominator, and like
+// FormatNumber otherwise.
+func FormatValue(v float64, decimals int) string {
+	if v == math.Round(v) {
+		return FormatNumber(v, decimals)
+	}
+	if p, q, ok := nearFraction(v); ok && q > 1 {
+		return fmt.Sprintf("%d/%d", p, q)
+	}
+	return FormatNumber(v, decimals)
+}
+
+// nearFraction finds the convergent of v's continued fraction that agrees
+// with v to about nine significant digits, giving up once the denominator
+// passes maxFractionDenominator.
+func nearFraction(v float64) (p, q int64, ok bool) {
+	if math.IsInf(v, 0) || math.IsNaN(v) || math.Abs(v) > 1e9 {
+		return 0, 0, false
+	}
+	tolerance := 1e-9 * max(1, math.Abs(v))
+	return convergent(v, maxFractionDenominator, func(p, q int64) bool {
+		return math.Abs(float64(p)/float64(q)-v) <= tolerance
+	})
+}
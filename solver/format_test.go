@@ -0,0 +1,57 @@
+package solver
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		v        float64
+		decimals int
+		want     string
+	}{
+		{2, 2, "2"},
+		{-11, 2, "-11"},
+		{2.5, 2, "2.50"},
+		{2.9999, 2, "3"},
+		{-0.001, 2, "0"},
+		{1.0 / 3, 4, "0.3333"},
+		{1.0 / 3, 0, "0"},
+	}
+	for _, tt := range tests {
+		if got := FormatNumber(tt.v, tt.decimals); got != tt.want {
+			t.Errorf("FormatNumber(%v, %d) = %q, want %q", tt.v, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		v        float64
+		decimals int
+		want     string
+	}{
+		{2, 2, "2"},
+		{1.0 / 3, 2, "1/3"},
+		{-2.0 / 3, 2, "-2/3"},
+		{2.5, 2, "5/2"},
+		{0.1 + 0.2, 2, "3/10"},
+		{7 - 1e-13, 2, "7"},
+		{math.Pi, 2, "3.14"},
+		{1.0 / 101, 4, "0.0099"},
+		{math.Sqrt2, 3, "1.414"},
+	}
+	for _, tt := range tests {
+		if got := FormatValue(tt.v, tt.decimals); got != tt.want {
+			t.Errorf("FormatValue(%v, %d) = %q, want %q", tt.v, tt.decimals, got, tt.want)
+		}
+	}
+
+	m := NewMatrix(2, 3)
+	m.data = [][]float64{{3, 0, 1}, {0, 7, 1}}
+	m.GaussianElimination()
+	if got, want := m.SolutionString(), "x = 1/3, y = 1/7"; got != want {
+		t.Errorf("SolutionString = %q, want %q", got, want)
+	}
+}
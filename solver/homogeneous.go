@@ -0,0 +1,89 @@
+package solver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IsHomogeneous reports whether m is a system Ax = 0: a single right-hand
+// side whose entries are all zero. Row operations keep a zero column zero,
+// so this holds before and after elimination.
+func (m *Matrix) IsHomogeneous() bool {
+	if m.CoefficientColumns() != m.cols-1 {
+		return false
+	}
+	for i := 0; i < m.rows; i++ {
+		if m.data[i][m.cols-1] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// pivotRows maps each coefficient column of a reduced matrix to the row
+// whose leading entry it holds, or -1 for the columns of free variables.
+func (m *Matrix) pivotRows() []int {
+	n := m.CoefficientColumns()
+	pivotRow := make([]int, n)
+	for j := range pivotRow {
+		pivotRow[j] = -1
+	}
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < n; j++ {
+			if !m.tol.isZero(m.data[i][j]) {
+				pivotRow[j] = i
+				break
+			}
+		}
+	}
+	return pivotRow
+}
+
+// NullSpaceBasis returns a basis of the solutions of Ax = 0 for a matrix in
+// reduced row echelon form, one vector per free variable: the free
+// variable set to 1, the other free variables to 0, and each pivot
+// variable to minus its row's entry in the free column. A matrix of full
+// column rank has an empty basis.
+func (m *Matrix) NullSpaceBasis() [][]float64 {
+	pivotRow := m.pivotRows()
+	var basis [][]float64
+	for f, r := range pivotRow {
+		if r >= 0 {
+			continue
+		}
+		v := make([]float64, len(pivotRow))
+		v[f] = 1
+		for j, r := range pivotRow {
+			// Skipping zeros keeps -0 out of the basis.
+			if r >= 0 && m.data[r][f] != 0 {
+				v[j] = -m.data[r][f]
+			}
+		}
+		basis = append(basis, v)
+	}
+	return basis
+}
+
+// TrivialSolution describes the only solution of a homogeneous system of
+// full rank, e.g. "Only the trivial solution: x = y = z = 0".
+func (m *Matrix) TrivialSolution() string {
+	names := make([]string, m.CoefficientColumns())
+	for j := range names {
+		names[j] = m.VariableName(j)
+	}
+	return "Only the trivial solution: " + strings.Join(names, " = ") + " = 0"
+}
+
+// NullSpaceString formats a null space basis as
+// "Null space basis: (-1, 1, 0), (2, 0, 1)".
+func NullSpaceString(basis [][]float64, decimals int) string {
+	vectors := make([]string, len(basis))
+	for i, v := range basis {
+		parts := make([]string, len(v))
+		for j, x := range v {
+			parts[j] = FormatValue(x, decimals)
+		}
+		vectors[i] = "(" + strings.Join(parts, ", ") + ")"
+	}
+	return fmt.Sprintf("Null space basis: %s", strings.Join(vectors, ", "))
+}
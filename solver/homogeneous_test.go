@@ -0,0 +1,50 @@
+package solver
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNullSpaceBasis(t *testing.T) {
+	tests := []struct {
+		equations []string
+		want      string
+	}{
+		{[]string{"x + y = 0", "2x + 2y = 0"}, "Null space basis: (-1, 1)"},
+		{[]string{"x + 2z = 0", "y - z = 0", "x + y + z = 0"}, "Null space basis: (-2, 1, 1)"},
+		{[]string{"x + y - z = 0", "2x + 2y - 2z = 0", "3x + 3y - 3z = 0"}, "Null space basis: (-1, 1, 0), (1, 0, 1)"},
+	}
+	for _, tt := range tests {
+		m, errs := Parse(tt.equations)
+		if m == nil {
+			t.Fatalf("Parse(%q): %v", tt.equations, errors.Join(errs...))
+		}
+		original := m.Clone()
+		m.GaussianElimination()
+		if !m.IsHomogeneous() {
+			t.Errorf("%q: not homogeneous after elimination", tt.equations)
+		}
+		basis := m.NullSpaceBasis()
+		if got := NullSpaceString(basis, 2); got != tt.want {
+			t.Errorf("%q: %s, want %s", tt.equations, got, tt.want)
+		}
+		for _, v := range basis {
+			for i, r := range Residuals(original.data, v) {
+				if r != 0 {
+					t.Errorf("%q: A%v is %v in row %d, want 0", tt.equations, v, r, i+1)
+				}
+			}
+		}
+	}
+}
+
+func TestIsHomogeneous(t *testing.T) {
+	m, _ := Parse([]string{"x + y = 0", "x - y = 0"})
+	if !m.IsHomogeneous() {
+		t.Error("x + y = 0, x - y = 0 is not homogeneous")
+	}
+	m, _ = Parse([]string{"x + y = 0", "x - y = 1"})
+	if m.IsHomogeneous() {
+		t.Error("x + y = 0, x - y = 1 is homogeneous")
+	}
+}
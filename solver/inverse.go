@@ -0,0 +1,57 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSingularMatrix is returned by Inverse for a matrix without an inverse.
+var ErrSingularMatrix = errors.New("matrix is singular")
+
+// AugmentIdentity returns [A | I] for the coefficient block A of m, ready
+// for Gauss-Jordan elimination. A must be square.
+func (m *Matrix) AugmentIdentity() (*Matrix, error) {
+	n := m.CoefficientColumns()
+	if m.rows != n {
+		return nil, fmt.Errorf("only square matrices can be inverted, got %d rows and %d columns", m.rows, n)
+	}
+	aug := NewMatrix(n, 2*n)
+	for i := 0; i < n; i++ {
+		copy(aug.data[i], m.data[i][:n])
+		aug.data[i][n+i] = 1
+	}
+	aug.coeffs = n
+	aug.pivot = m.pivot
+	aug.labels = m.labels
+	aug.decimals = m.decimals
+	aug.tol = m.tol
+	aug.snapshots = m.snapshots
+	aug.names = m.names
+	return aug, nil
+}
+
+// InverseBlock returns the right half of a reduced [A | I], which is the
+// inverse of A, or ErrSingularMatrix if A did not reduce to the identity.
+func (m *Matrix) InverseBlock() (*Matrix, error) {
+	n := m.CoefficientColumns()
+	if m.CoefficientRank() < n {
+		return nil, ErrSingularMatrix
+	}
+	inv := NewMatrix(n, n)
+	inv.decimals = m.decimals
+	for i := range inv.data {
+		copy(inv.data[i], m.data[i][n:])
+	}
+	return inv, nil
+}
+
+// Inverse returns the inverse of the coefficient block of m, found by
+// running GaussianElimination on [A | I].
+func (m *Matrix) Inverse() (*Matrix, error) {
+	aug, err := m.AugmentIdentity()
+	if err != nil {
+		return nil, err
+	}
+	aug.GaussianElimination()
+	return aug.InverseBlock()
+}
@@ -0,0 +1,34 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestInverse(t *testing.T) {
+	m := NewMatrix(3, 4)
+	m.data = [][]float64{{2, 1, -1, 8}, {-3, -1, 2, -11}, {-2, 1, 2, -3}}
+	inv, err := m.Inverse()
+	if err != nil {
+		t.Fatalf("Inverse: %v", err)
+	}
+	want := [][]float64{{4, 3, -1}, {-2, -2, 1}, {5, 4, -1}}
+	for i := range want {
+		for j := range want[i] {
+			if math.Abs(inv.data[i][j]-want[i][j]) > 1e-9 {
+				t.Fatalf("inverse = %v, want %v", inv.data, want)
+			}
+		}
+	}
+
+	singular := NewMatrix(2, 3)
+	singular.data = [][]float64{{1, 2, 0}, {2, 4, 0}}
+	if _, err := singular.Inverse(); !errors.Is(err, ErrSingularMatrix) {
+		t.Errorf("singular matrix: err = %v, want ErrSingularMatrix", err)
+	}
+
+	if _, err := NewMatrix(2, 4).Inverse(); err == nil {
+		t.Error("non-square coefficient block was inverted")
+	}
+}
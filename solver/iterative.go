@@ -0,0 +1,131 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrNotConverged is returned by Jacobi and GaussSeidel when the iterates
+// have not settled within the iteration limit, or have blown up.
+var ErrNotConverged = errors.New("iteration did not converge")
+
+// Iteration limits for the Jacobi and Gauss-Seidel methods in the UI.
+const (
+	maxIterations = 50
+	iterationTol  = 1e-6
+)
+
+// Jacobi solves the square system m by Jacobi iteration from x = 0: every
+// unknown is recomputed from its own equation using the previous iterate.
+// It stops when no unknown changes by more than tol, and returns the
+// iterate with one step per iteration.
+func (m *Matrix) Jacobi(maxIter int, tol float64) ([]float64, []string, error) {
+	return m.iterate(maxIter, tol, false)
+}
+
+// GaussSeidel is Jacobi but uses each new value as soon as it is computed,
+// which usually converges faster.
+func (m *Matrix) GaussSeidel(maxIter int, tol float64) ([]float64, []string, error) {
+	return m.iterate(maxIter, tol, true)
+}
+
+func (m *Matrix) iterate(maxIter int, tol float64, inPlace bool) ([]float64, []string, error) {
+	n := m.CoefficientColumns()
+	if m.rows != n {
+		return nil, nil, fmt.Errorf("iterative methods need a square system, got %d equations in %d unknowns", m.rows, n)
+	}
+	for i := 0; i < n; i++ {
+		if m.tol.isZero(m.data[i][i]) {
+			return nil, nil, fmt.Errorf("zero on the diagonal in %s", m.labels.label(i))
+		}
+	}
+
+	steps := []string{}
+	if !m.diagonallyDominant() {
+		steps = append(steps, "The matrix is not diagonally dominant, so the iteration may not converge")
+	}
+	x := make([]float64, n)
+	for k := 1; k <= maxIter; k++ {
+		prev := append([]float64(nil), x...)
+		// Jacobi reads only the previous iterate; Gauss-Seidel reads x,
+		// which already holds this iteration's values for earlier rows.
+		src := prev
+		if inPlace {
+			src = x
+		}
+		change := 0.0
+		for i := 0; i < n; i++ {
+			sum := m.data[i][n]
+			for j := 0; j < n; j++ {
+				if j != i {
+					sum -= m.data[i][j] * src[j]
+				}
+			}
+			x[i] = sum / m.data[i][i]
+			change = math.Max(change, math.Abs(x[i]-prev[i]))
+		}
+		steps = append(steps, fmt.Sprintf("Iteration %d: %s", k, m.formatValues(x)))
+
+		if math.IsNaN(change) || math.IsInf(change, 0) || change > 1e12 {
+			return x, steps, fmt.Errorf("%w: the iterates diverge", ErrNotConverged)
+		}
+		if change < tol {
+			return x, steps, nil
+		}
+	}
+	return x, steps, fmt.Errorf("%w in %d iterations", ErrNotConverged, maxIter)
+}
+
+// diagonallyDominant reports whether every diagonal coefficient outweighs
+// the rest of its row, which guarantees that both iterations converge.
+func (m *Matrix) diagonallyDominant() bool {
+	n := m.CoefficientColumns()
+	for i := 0; i < m.rows; i++ {
+		off := 0.0
+		for j := 0; j < n; j++ {
+			if j != i {
+				off += math.Abs(m.data[i][j])
+			}
+		}
+		if math.Abs(m.data[i][i]) < off {
+			return false
+		}
+	}
+	return true
+}
+
+// formatValues formats one value per unknown as "x = 1, y = -0.50, ...".
+func (m *Matrix) formatValues(x []float64) string {
+	parts := make([]string, len(x))
+	for i, v := range x {
+		parts[i] = fmt.Sprintf("%s = %s", m.VariableName(i), FormatNumber(v, m.decimals))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// IterativeSteps lists the iterates of the Jacobi or Gauss-Seidel method
+// for the step list, ending with whether it converged, and returns the last
+// iterate with the error of the method.
+func IterativeSteps(m *Matrix, gaussSeidel bool) ([]float64, []string, error) {
+	name, solve := "Jacobi", m.Jacobi
+	if gaussSeidel {
+		name, solve = "Gauss-Seidel", m.GaussSeidel
+	}
+	x, iterations, err := solve(maxIterations, iterationTol)
+	if err != nil && iterations == nil {
+		return nil, []string{name + " does not apply: " + err.Error()}, err
+	}
+	steps := append([]string{name + " iteration from x = 0"}, iterations...)
+	if err != nil {
+		return x, append(steps, name+" stopped: "+err.Error()), err
+	}
+	count := 0
+	for _, step := range iterations {
+		if strings.HasPrefix(step, "Iteration ") {
+			count++
+		}
+	}
+	return x, append(steps, fmt.Sprintf("Converged after %d iterations", count)), nil
+}
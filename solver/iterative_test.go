@@ -0,0 +1,71 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestIterativeMethods(t *testing.T) {
+	m := NewMatrix(3, 4)
+	m.data = [][]float64{{10, -1, 2, 6}, {-1, 11, -1, 25}, {2, -1, 10, -11}}
+	want, _, err := m.Cramer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jx, jacobi, err := m.Jacobi(100, 1e-9)
+	if err != nil {
+		t.Fatalf("Jacobi: %v", err)
+	}
+	gx, seidel, err := m.GaussSeidel(100, 1e-9)
+	if err != nil {
+		t.Fatalf("GaussSeidel: %v", err)
+	}
+	for i := range want {
+		if math.Abs(jx[i]-want[i]) > 1e-7 || math.Abs(gx[i]-want[i]) > 1e-7 {
+			t.Errorf("%s: Jacobi %v, Gauss-Seidel %v, want %v", VariableName(i), jx[i], gx[i], want[i])
+		}
+	}
+	if len(seidel) >= len(jacobi) {
+		t.Errorf("Gauss-Seidel took %d iterations, Jacobi %d", len(seidel), len(jacobi))
+	}
+	if !strings.HasPrefix(jacobi[0], "Iteration 1: x = 0.60, ") {
+		t.Errorf("first Jacobi step = %q", jacobi[0])
+	}
+
+	diverging := NewMatrix(2, 3)
+	diverging.data = [][]float64{{1, 3, 4}, {2, 1, 3}}
+	_, steps, err := diverging.Jacobi(maxIterations, iterationTol)
+	if !errors.Is(err, ErrNotConverged) {
+		t.Errorf("diverging system: err = %v", err)
+	}
+	if !strings.Contains(steps[0], "not diagonally dominant") {
+		t.Errorf("no warning for a non-dominant matrix: %q", steps[0])
+	}
+
+	zero := NewMatrix(2, 3)
+	zero.data = [][]float64{{0, 1, 1}, {1, 0, 1}}
+	if _, _, err := zero.GaussSeidel(maxIterations, iterationTol); err == nil {
+		t.Error("zero diagonal accepted")
+	}
+}
+
+func TestIterativeSteps(t *testing.T) {
+	m := NewMatrix(2, 3)
+	m.data = [][]float64{{4, 1, 5}, {1, 3, 4}}
+	x, steps, err := IterativeSteps(m, true)
+	if err != nil || steps[0] != "Gauss-Seidel iteration from x = 0" || !strings.HasPrefix(steps[len(steps)-1], "Converged after ") {
+		t.Errorf("steps = %q, err %v", steps, err)
+	}
+	if math.Abs(x[0]-1) > 1e-5 || math.Abs(x[1]-1) > 1e-5 {
+		t.Errorf("iterate = %v, want x = 1, y = 1", x)
+	}
+
+	diverging := NewMatrix(2, 3)
+	diverging.data = [][]float64{{1, 2, 3}, {3, 1, 4}}
+	if _, steps, err := IterativeSteps(diverging, false); !errors.Is(err, ErrNotConverged) || !strings.HasPrefix(steps[len(steps)-1], "Jacobi stopped: ") {
+		t.Errorf("diverging system: err = %v, steps %q", err, steps)
+	}
+}
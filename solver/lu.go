@@ -0,0 +1,126 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// LUDecompose factors the square coefficient block A of m as PA = LU with
+// partial pivoting: L is unit lower triangular, U is upper triangular and
+// row i of PA is row perm[i] of A. It returns ErrSingularMatrix if a column
+// has no non-zero pivot.
+func (m *Matrix) LUDecompose() (L, U *Matrix, perm []int, err error) {
+	n := m.CoefficientColumns()
+	if m.rows != n {
+		return nil, nil, nil, fmt.Errorf("only square matrices can be factored, got %d rows and %d columns", m.rows, n)
+	}
+	L, U = NewMatrix(n, n), NewMatrix(n, n)
+	L.decimals, U.decimals = m.decimals, m.decimals
+	perm = make([]int, n)
+	for i := 0; i < n; i++ {
+		copy(U.data[i], m.data[i][:n])
+		perm[i] = i
+	}
+
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(U.data[i][k]) > math.Abs(U.data[p][k]) {
+				p = i
+			}
+		}
+		if m.tol.isZero(U.data[p][k]) {
+			return nil, nil, nil, ErrSingularMatrix
+		}
+		if p != k {
+			// The multipliers found so far move with their rows.
+			if err := U.SwapRows(p, k); err != nil {
+				return nil, nil, nil, err
+			}
+			if err := L.SwapRows(p, k); err != nil {
+				return nil, nil, nil, err
+			}
+			perm[p], perm[k] = perm[k], perm[p]
+		}
+		for i := k + 1; i < n; i++ {
+			f := U.data[i][k] / U.data[k][k]
+			L.data[i][k] = f
+			U.AddMultipleOfRow(i, k, -f)
+			U.data[i][k] = 0
+		}
+	}
+	for i := 0; i < n; i++ {
+		L.data[i][i] = 1
+	}
+	return L, U, perm, nil
+}
+
+// forwardSubstitute solves Ly = Pb for the unit lower triangular L and
+// the row order perm from LUDecompose.
+func forwardSubstitute(L *Matrix, perm []int, b []float64) []float64 {
+	y := make([]float64, len(b))
+	for i := range y {
+		y[i] = b[perm[i]]
+		for j := 0; j < i; j++ {
+			y[i] -= L.data[i][j] * y[j]
+		}
+	}
+	return y
+}
+
+// backSubstitute solves Ux = y for the upper triangular U.
+func backSubstitute(U *Matrix, y []float64) []float64 {
+	x := make([]float64, len(y))
+	for i := len(x) - 1; i >= 0; i-- {
+		x[i] = y[i]
+		for j := i + 1; j < len(x); j++ {
+			x[i] -= U.data[i][j] * x[j]
+		}
+		x[i] /= U.data[i][i]
+	}
+	return x
+}
+
+// SolveLU solves Ax = b from the factors of A returned by LUDecompose.
+func SolveLU(L, U *Matrix, perm []int, b []float64) []float64 {
+	return backSubstitute(U, forwardSubstitute(L, perm, b))
+}
+
+// LUSteps describes the LU factorization of the coefficient block of m and
+// the forward and back substitution solving it with the first right-hand
+// side, as lines for the step list.
+func LUSteps(m *Matrix) []string {
+	L, U, perm, err := m.LUDecompose()
+	if err != nil {
+		return []string{"\nLU factorization: " + err.Error()}
+	}
+
+	rows := make([]string, len(perm))
+	for i, p := range perm {
+		rows[i] = m.labels.label(p)
+	}
+	steps := []string{"\nLU factorization (PA = LU):", "P orders the rows " + strings.Join(rows, ", "), "L:"}
+	steps = append(steps, strings.Split(strings.TrimSuffix(L.GetPlainMatrixString(), "\n"), "\n")...)
+	steps = append(steps, "U:")
+	steps = append(steps, strings.Split(strings.TrimSuffix(U.GetPlainMatrixString(), "\n"), "\n")...)
+
+	b := make([]float64, m.rows)
+	for i := range b {
+		b[i] = m.data[i][m.CoefficientColumns()]
+	}
+	y := forwardSubstitute(L, perm, b)
+	x := backSubstitute(U, y)
+	return append(steps,
+		"Forward substitution Ly = Pb: y = "+formatVector(y, m.decimals),
+		"Back substitution Ux = y: x = "+formatVector(x, m.decimals))
+}
+
+// formatVector formats v like a matrix row, e.g. "[1 -0.50 2]".
+func formatVector(v []float64, decimals int) string {
+	parts := make([]string, len(v))
+	for i, x := range v {
+		parts[i] = FormatNumber(x, decimals)
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
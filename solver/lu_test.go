@@ -0,0 +1,72 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestLUDecompose(t *testing.T) {
+	m := NewMatrix(3, 4)
+	m.data = [][]float64{{2, 1, -1, 8}, {-3, -1, 2, -11}, {-2, 1, 2, -3}}
+	L, U, perm, err := m.LUDecompose()
+	if err != nil {
+		t.Fatalf("LUDecompose: %v", err)
+	}
+	if perm[0] != 1 {
+		t.Errorf("perm = %v, want the largest pivot (row 2) first", perm)
+	}
+	for i := 0; i < 3; i++ {
+		if L.data[i][i] != 1 {
+			t.Errorf("L[%d][%d] = %v, want 1", i, i, L.data[i][i])
+		}
+		for j := 0; j < 3; j++ {
+			if j > i && L.data[i][j] != 0 || j < i && U.data[i][j] != 0 {
+				t.Fatalf("factors are not triangular:\nL = %v\nU = %v", L.data, U.data)
+			}
+			// (LU)[i][j] must equal A[perm[i]][j].
+			sum := 0.0
+			for k := 0; k < 3; k++ {
+				sum += L.data[i][k] * U.data[k][j]
+			}
+			if math.Abs(sum-m.data[perm[i]][j]) > 1e-9 {
+				t.Errorf("(LU)[%d][%d] = %v, want %v", i, j, sum, m.data[perm[i]][j])
+			}
+		}
+	}
+
+	x := SolveLU(L, U, perm, []float64{8, -11, -3})
+	for i, want := range []float64{2, 3, -1} {
+		if math.Abs(x[i]-want) > 1e-9 {
+			t.Errorf("x = %v, want [2 3 -1]", x)
+			break
+		}
+	}
+
+	singular := NewMatrix(2, 3)
+	singular.data = [][]float64{{1, 2, 0}, {2, 4, 0}}
+	if _, _, _, err := singular.LUDecompose(); !errors.Is(err, ErrSingularMatrix) {
+		t.Errorf("singular matrix: err = %v, want ErrSingularMatrix", err)
+	}
+}
+
+func TestLUSteps(t *testing.T) {
+	m := NewMatrix(2, 3)
+	m.data = [][]float64{{1, 1, 3}, {2, 1, 4}}
+	steps := LUSteps(m)
+	if steps[1] != "P orders the rows L2, L1" {
+		t.Errorf("permutation shown as %q", steps[1])
+	}
+	// The factors are shown with the matrix's decimals, not rounded.
+	if got, want := strings.Join(steps[2:8], "\n"), "L:\n[1 0]\n[0.50 1]\nU:\n[2 1]\n[0 0.50]"; got != want {
+		t.Errorf("factors shown as\n%s\nwant\n%s", got, want)
+	}
+	m.SetDecimals(3)
+	if got := LUSteps(m)[4]; got != "[0.500 1]" {
+		t.Errorf("second row of L with 3 decimals = %q, want [0.500 1]", got)
+	}
+	if last := steps[len(steps)-1]; last != "Back substitution Ux = y: x = [1 2]" {
+		t.Errorf("last line = %q", last)
+	}
+}
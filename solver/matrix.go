@@ -0,0 +1,552 @@
+package solver
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+type Matrix struct {
+	rows      int
+	cols      int
+	data      [][]float64
+	singular  *Singularity
+	stats     Stats
+	trace     []Step
+	pivot     PivotStrategy
+	labels    RowLabels
+	debug     bool   // halt elimination at the first anomaly
+	decimals  int    // shown in matrix strings and steps, see FormatNumber
+	anomaly   string // why a debug run halted, empty if it didn't
+	coeffs    int    // columns before the augmented bar, 0 for cols-1
+	echelon   bool   // stop at row echelon form, see ForwardElimination
+	tol       Tolerance
+	names     []string // variable of each column, see VariableName
+	snapshots bool     // keep Step.After in the trace, see SetSnapshots
+}
+
+// Default thresholds of the elimination, see Tolerance.
+const (
+	DefaultEpsilon     = 1e-10
+	DefaultRoundDigits = 10
+)
+
+// Tolerance tunes how the elimination deals with floating-point noise. The
+// zero value uses the defaults, which suit coefficients of everyday size;
+// systems with very small or very large coefficients may need others.
+type Tolerance struct {
+	// Epsilon is the magnitude below which an entry counts as zero, so
+	// it is neither used as a pivot nor eliminated. 0 means
+	// DefaultEpsilon. Too large and small genuine entries are dropped;
+	// too small and rounding noise is taken for a pivot.
+	Epsilon float64
+	// RoundDigits is how many decimals the entries of a row are rounded
+	// to once a pass has changed it, to clear noise such as
+	// 0.30000000000000004. 0 means DefaultRoundDigits and a negative
+	// value turns the rounding off.
+	RoundDigits int
+}
+
+// isZero reports whether x is within Epsilon of zero.
+func (t Tolerance) isZero(x float64) bool {
+	eps := t.Epsilon
+	if eps <= 0 {
+		eps = DefaultEpsilon
+	}
+	return math.Abs(x) < eps
+}
+
+// round rounds x to RoundDigits decimals.
+func (t Tolerance) round(x float64) float64 {
+	digits := t.RoundDigits
+	if digits == 0 {
+		digits = DefaultRoundDigits
+	}
+	if digits < 0 {
+		return x
+	}
+	multiplier := math.Pow(10, float64(digits))
+	return math.Round(x*multiplier) / multiplier
+}
+
+// RowLabels controls how rows are named in step descriptions. The zero
+// value means the classic 1-indexed "L1, L2, L3".
+type RowLabels struct {
+	Prefix string
+	Base   int
+}
+
+var (
+	OneIndexedLabels  = RowLabels{Prefix: "L", Base: 1}
+	ZeroIndexedLabels = RowLabels{Prefix: "R", Base: 0}
+)
+
+func (l RowLabels) label(row int) string {
+	if l.Prefix == "" {
+		l = OneIndexedLabels
+	}
+	return fmt.Sprintf("%s%d", l.Prefix, row+l.Base)
+}
+
+// Step describes one entry of the step list returned by
+// GaussianElimination; trace[i] belongs to steps[i].
+type Step struct {
+	Column   int         // column being eliminated, or -1 for steps not tied to one
+	PivotRow int         // row of the pivot m.data[pivotRow][column], -1 if none
+	After    [][]float64 // the matrix once the step is done, nil without SetSnapshots
+}
+
+// Stats counts the work done by the last GaussianElimination.
+type Stats struct {
+	Swaps       int
+	Scalings    int
+	RowAdds     int
+	Elapsed     time.Duration
+	Determinant float64
+	MinPivot    float64 // smallest coefficient pivot magnitude
+	MaxPivot    float64 // largest coefficient pivot magnitude
+}
+
+// IllConditioned is the pivot ratio above which a float result is flagged
+// as possibly inaccurate: about 6 of the 16 significant digits are gone.
+const IllConditioned = 1e6
+
+// ConditionEstimate returns the ratio of the largest to the smallest
+// coefficient pivot magnitude, a rough estimate of the condition number of
+// the coefficient matrix, or 0 if no pivot was found.
+func (s Stats) ConditionEstimate() float64 {
+	if s.MinPivot == 0 {
+		return 0
+	}
+	return s.MaxPivot / s.MinPivot
+}
+
+// Summary describes the row operations and the time taken, e.g.
+// "Row operations: 1 swap, 3 scalings, 6 additions in 12.5µs".
+func (s Stats) Summary() string {
+	count := func(n int, what string) string {
+		if n == 1 {
+			return "1 " + what
+		}
+		return fmt.Sprintf("%d %ss", n, what)
+	}
+	return fmt.Sprintf("Row operations: %s, %s, %s in %v",
+		count(s.Swaps, "swap"), count(s.Scalings, "scaling"), count(s.RowAdds, "addition"), s.Elapsed)
+}
+
+// Singularity records where elimination first failed to find a pivot.
+type Singularity struct {
+	Step   int // 1-based elimination step (the row being pivoted)
+	Column int // 0-based column that had no non-zero entry
+}
+
+// Kind classifies a reduced system by how many solutions it has.
+type Kind int
+
+const (
+	Unique Kind = iota
+	Infinite
+	None
+)
+
+func NewMatrix(rows, cols int) *Matrix {
+	data := make([][]float64, rows)
+	for i := range data {
+		data[i] = make([]float64, cols)
+	}
+	return &Matrix{
+		rows:     rows,
+		cols:     cols,
+		data:     data,
+		decimals: DefaultDecimals,
+	}
+}
+
+// DefaultDecimals is how many decimals are shown unless changed with
+// -precision or F6.
+const DefaultDecimals = 2
+
+// SetPivotStrategy replaces the pivot selection used by GaussianElimination;
+// nil restores PartialPivot.
+func (m *Matrix) SetPivotStrategy(strategy PivotStrategy) {
+	m.pivot = strategy
+}
+
+// SetRowLabels sets the letter and index base used for row names in the
+// steps, e.g. ("R", 0) for R0, R1, R2.
+func (m *Matrix) SetRowLabels(prefix string, base int) {
+	m.labels = RowLabels{Prefix: prefix, Base: base}
+}
+
+// Labels returns the row names used in the steps, OneIndexedLabels unless
+// SetRowLabels chose others.
+func (m *Matrix) Labels() RowLabels {
+	if m.labels.Prefix == "" {
+		return OneIndexedLabels
+	}
+	return m.labels
+}
+
+// At returns the entry at row, col.
+func (m *Matrix) At(row, col int) float64 {
+	return m.data[row][col]
+}
+
+// Rows returns the number of rows.
+func (m *Matrix) Rows() int {
+	return m.rows
+}
+
+// Cols returns the number of columns, right-hand sides included.
+func (m *Matrix) Cols() int {
+	return m.cols
+}
+
+// Set replaces the entry at row, col.
+func (m *Matrix) Set(row, col int, v float64) {
+	m.data[row][col] = v
+}
+
+// Data returns a copy of the entries, one slice per row.
+func (m *Matrix) Data() [][]float64 {
+	return m.copyData()
+}
+
+// SetDebug makes GaussianElimination halt at the first anomaly, a NaN or
+// infinite entry or a column whose pivot was lost to the tolerance, see
+// Anomaly. Columns that are free because their entries cancel exactly are
+// not anomalies.
+func (m *Matrix) SetDebug(debug bool) {
+	m.debug = debug
+}
+
+// SetSnapshots makes the trace keep a copy of the matrix after every step
+// in Step.After, for showing the steps as grids. Without it After is nil,
+// sparing large systems the copies.
+func (m *Matrix) SetSnapshots(snapshots bool) {
+	m.snapshots = snapshots
+}
+
+// Anomaly describes why a debug run halted, or is empty if it didn't.
+func (m *Matrix) Anomaly() string {
+	return m.anomaly
+}
+
+// SetDecimals sets how many decimals matrix strings and steps show.
+func (m *Matrix) SetDecimals(decimals int) {
+	m.decimals = decimals
+}
+
+// Decimals returns how many decimals matrix strings and steps show.
+func (m *Matrix) Decimals() int {
+	return m.decimals
+}
+
+// SetTolerance replaces the zero threshold and rounding of the elimination.
+func (m *Matrix) SetTolerance(tol Tolerance) {
+	m.tol = tol
+}
+
+// Trace returns one Step per entry of the step list of the last
+// GaussianElimination.
+func (m *Matrix) Trace() []Step {
+	return m.trace
+}
+
+// Stats returns the work done by the last GaussianElimination.
+func (m *Matrix) Stats() Stats {
+	return m.stats
+}
+
+// Singular returns where the last GaussianElimination first found no
+// pivot, or nil if every column had one.
+func (m *Matrix) Singular() *Singularity {
+	return m.singular
+}
+
+// Matrix operations
+// SwapRows exchanges two rows. It leaves m unchanged and returns an error
+// if either row is out of range.
+func (m *Matrix) SwapRows(row1, row2 int) error {
+	if row1 < 0 || row1 >= m.rows || row2 < 0 || row2 >= m.rows {
+		return fmt.Errorf("cannot swap rows %d and %d of a matrix with %d rows", row1+1, row2+1, m.rows)
+	}
+	m.data[row1], m.data[row2] = m.data[row2], m.data[row1]
+	return nil
+}
+
+func (m *Matrix) MultiplyRow(row int, scalar float64) {
+	for j := 0; j < m.cols; j++ {
+		m.data[row][j] *= scalar
+	}
+}
+
+func (m *Matrix) AddMultipleOfRow(targetRow, sourceRow int, scalar float64) {
+	for j := 0; j < m.cols; j++ {
+		m.data[targetRow][j] += scalar * m.data[sourceRow][j]
+	}
+}
+
+// roundRow rounds away the floating-point noise, such as the tail of
+// 0.30000000000000004, that row operations left in row. The elimination
+// rounds only the rows it changes; rounding to fewer places would
+// compound over the passes.
+func (m *Matrix) roundRow(row int) {
+	for j, v := range m.data[row] {
+		m.data[row][j] = m.tol.round(v)
+	}
+}
+
+// SetCoefficientColumns moves the augmented bar so that the first n columns
+// are coefficients and the rest are right-hand sides.
+func (m *Matrix) SetCoefficientColumns(n int) error {
+	if n < 1 || n >= m.cols {
+		return fmt.Errorf("coefficient block must have between 1 and %d columns, got %d", m.cols-1, n)
+	}
+	m.coeffs = n
+	return nil
+}
+
+// CoefficientColumns returns how many columns precede the augmented bar.
+func (m *Matrix) CoefficientColumns() int {
+	if m.coeffs > 0 {
+		return m.coeffs
+	}
+	return m.cols - 1
+}
+
+// GetMatrixString formats the matrix as an augmented system, with a bar
+// between the coefficients and the right-hand side.
+func (m *Matrix) GetMatrixString() string {
+	return m.formatRows(m.CoefficientColumns())
+}
+
+// GetPlainMatrixString formats the matrix without an augmented bar, for
+// coefficient matrices, inverses and factors.
+func (m *Matrix) GetPlainMatrixString() string {
+	return m.formatRows(-1)
+}
+
+// formatRows writes one bracketed line per row, with a "|" before column
+// bar; a negative bar leaves it out.
+func (m *Matrix) formatRows(bar int) string {
+	return FormatRows(m.data, bar, m.decimals)
+}
+
+// FormatRows formats entries like GetMatrixString, such as the matrix
+// after a Step: one bracketed line per row with a "|" before column bar,
+// left out if bar is negative.
+func FormatRows(data [][]float64, bar, decimals int) string {
+	var result strings.Builder
+	for _, row := range data {
+		result.WriteString("[")
+		for j, v := range row {
+			if j > 0 {
+				result.WriteString(" ")
+			}
+			if j == bar && j > 0 {
+				result.WriteString("| ")
+			}
+			result.WriteString(FormatNumber(v, decimals))
+		}
+		result.WriteString("]\n")
+	}
+	return result.String()
+}
+
+// Determinant returns the determinant of the coefficient block computed by
+// the last GaussianElimination, or false if the block is not square.
+func (m *Matrix) Determinant() (float64, bool) {
+	if m.rows != m.CoefficientColumns() {
+		return 0, false
+	}
+	return m.stats.Determinant, true
+}
+
+// Rank returns the rank of the coefficient block A and of the augmented
+// matrix [A|b] of a matrix already reduced by GaussianElimination.
+func (m *Matrix) Rank() (coefficients, augmented int) {
+	return m.rankOf(m.CoefficientColumns()), m.rankOf(m.cols)
+}
+
+// CoefficientRank is the rank of the coefficient block, see Rank.
+func (m *Matrix) CoefficientRank() int {
+	return m.rankOf(m.CoefficientColumns())
+}
+
+// rankOf counts the rows of a reduced matrix that have a non-zero entry in
+// the first cols columns.
+func (m *Matrix) rankOf(cols int) int {
+	rank := 0
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < cols; j++ {
+			if !m.tol.isZero(m.data[i][j]) {
+				rank++
+				break
+			}
+		}
+	}
+	return rank
+}
+
+// Classify inspects a matrix already reduced by GaussianElimination. If
+// rank(A) < rank([A|b]) some row reads 0 = c and the system is
+// inconsistent; otherwise every column of A without a pivot, n - rank(A) of
+// them, leaves a free variable.
+func (m *Matrix) Classify() Kind {
+	rank, augmented := m.Rank()
+	switch {
+	case rank < augmented:
+		return None
+	case rank < m.CoefficientColumns():
+		return Infinite
+	}
+	return Unique
+}
+
+// ParseAugmentedRows builds a matrix from rows of numbers separated by
+// spaces or commas, such as "2 1 -1 | 8" or "1, 0 | 1, 2" for two
+// right-hand sides. Entries may be fractions like 1/3. A "|" marks where
+// the coefficients end; it must sit in the same place in every row, and
+// when no row has one the last column is the right-hand side.
+func ParseAugmentedRows(lines []string) (*Matrix, error) {
+	var data [][]float64
+	bar := -1
+	for i, line := range lines {
+		fields := strings.FieldsFunc(strings.ReplaceAll(line, "|", " | "), func(r rune) bool {
+			return r == ' ' || r == ',' || r == '\t'
+		})
+		row := []float64{}
+		rowBar := -1
+		for _, f := range fields {
+			if f == "|" {
+				if rowBar >= 0 {
+					return nil, fmt.Errorf("row %d has more than one '|'", i+1)
+				}
+				rowBar = len(row)
+				continue
+			}
+			v, err := ParseNumber(f)
+			if err != nil {
+				return nil, fmt.Errorf("row %d, column %d: %v %q", i+1, len(row)+1, err, f)
+			}
+			row = append(row, v)
+		}
+		if i > 0 && len(row) != len(data[0]) {
+			return nil, fmt.Errorf("row %d has %d entries, expected %d", i+1, len(row), len(data[0]))
+		}
+		if i > 0 && rowBar != bar {
+			return nil, fmt.Errorf("row %d puts the '|' in a different column than row 1", i+1)
+		}
+		bar = rowBar
+		data = append(data, row)
+	}
+	if len(data) == 0 || len(data[0]) < 2 {
+		return nil, fmt.Errorf("matrix needs at least one row with two entries")
+	}
+
+	m := NewMatrix(len(data), len(data[0]))
+	m.data = data
+	if bar >= 0 {
+		if err := m.SetCoefficientColumns(bar); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// matrixJSON is the serialized form of a Matrix.
+type matrixJSON struct {
+	Rows int         `json:"rows"`
+	Cols int         `json:"cols"`
+	Data [][]float64 `json:"data"`
+	// Coeffs is the number of coefficient columns when the augmented bar
+	// isn't before the last column.
+	Coeffs int `json:"coefficient_columns,omitempty"`
+}
+
+// Clone returns a copy of m with its own entries, so that m can be
+// eliminated while the copy keeps the system as it was.
+func (m *Matrix) Clone() *Matrix {
+	c := *m
+	c.data = m.copyData()
+	c.trace = append([]Step(nil), m.trace...)
+	return &c
+}
+
+// snapshot returns the copy of the entries a Step keeps, or nil without
+// SetSnapshots.
+func (m *Matrix) snapshot() [][]float64 {
+	if !m.snapshots {
+		return nil
+	}
+	return m.copyData()
+}
+
+// copyData returns a deep copy of the entries. The rows share one
+// allocation, as the elimination copies the matrix after every step.
+func (m *Matrix) copyData() [][]float64 {
+	data := make([][]float64, m.rows)
+	cells := make([]float64, m.rows*m.cols)
+	for i := range data {
+		data[i] = cells[i*m.cols : (i+1)*m.cols : (i+1)*m.cols]
+		copy(data[i], m.data[i])
+	}
+	return data
+}
+
+func (m *Matrix) MarshalJSON() ([]byte, error) {
+	return json.Marshal(matrixJSON{Rows: m.rows, Cols: m.cols, Data: m.data, Coeffs: m.coeffs})
+}
+
+func (m *Matrix) UnmarshalJSON(b []byte) error {
+	var mj matrixJSON
+	if err := json.Unmarshal(b, &mj); err != nil {
+		return err
+	}
+	if len(mj.Data) != mj.Rows {
+		return fmt.Errorf("matrix has %d rows of data, expected %d", len(mj.Data), mj.Rows)
+	}
+	for i, row := range mj.Data {
+		if len(row) != mj.Cols {
+			return fmt.Errorf("matrix row %d has %d columns, expected %d", i+1, len(row), mj.Cols)
+		}
+	}
+	if mj.Coeffs < 0 || mj.Coeffs >= mj.Cols && mj.Coeffs != 0 {
+		return fmt.Errorf("matrix has %d coefficient columns out of %d", mj.Coeffs, mj.Cols)
+	}
+	m.rows = mj.Rows
+	m.cols = mj.Cols
+	m.data = mj.Data
+	m.coeffs = mj.Coeffs
+	m.singular = nil
+	m.decimals = DefaultDecimals
+	return nil
+}
+
+// lostPivot reports the row of the largest entry of col from startRow on,
+// if that entry isn't exactly zero: the column has no pivot only because
+// the entries are within epsilon of zero.
+func (m *Matrix) lostPivot(col, startRow int) (row int, ok bool) {
+	row = -1
+	for i := startRow; i < m.rows; i++ {
+		if m.data[i][col] != 0 && (row < 0 || math.Abs(m.data[i][col]) > math.Abs(m.data[row][col])) {
+			row = i
+		}
+	}
+	return row, row >= 0
+}
+
+// findNonFinite reports the first NaN or infinite entry, if any.
+func (m *Matrix) findNonFinite() (row, col int, ok bool) {
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			if math.IsNaN(m.data[i][j]) || math.IsInf(m.data[i][j], 0) {
+				return i, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
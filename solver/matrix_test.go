@@ -0,0 +1,217 @@
+package solver
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMatrixJSONRoundTrip(t *testing.T) {
+	m := NewMatrix(3, 4)
+	m.data[0] = []float64{2, 1, -1, 8}
+	m.data[1] = []float64{-3, -1, 2, -11}
+	m.data[2] = []float64{-2, 1, 2, -3.5}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got Matrix
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got.rows != m.rows || got.cols != m.cols {
+		t.Fatalf("got %dx%d matrix, want %dx%d", got.rows, got.cols, m.rows, m.cols)
+	}
+	for i := range m.data {
+		for j := range m.data[i] {
+			if got.data[i][j] != m.data[i][j] {
+				t.Errorf("data[%d][%d] = %v, want %v", i, j, got.data[i][j], m.data[i][j])
+			}
+		}
+	}
+}
+
+func TestMatrixUnmarshalRejectsRaggedData(t *testing.T) {
+	var m Matrix
+	err := json.Unmarshal([]byte(`{"rows":2,"cols":3,"data":[[1,2,3],[4,5]]}`), &m)
+	if err == nil {
+		t.Fatal("Unmarshal accepted a row with the wrong number of columns")
+	}
+}
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		rows                [][]float64
+		rank, augmentedRank int
+	}{
+		{[][]float64{{1, 0, 2}, {0, 1, 3}}, 2, 2},
+		{[][]float64{{1, 1, 2}, {2, 2, 4}}, 1, 1},
+		{[][]float64{{1, 1, 2}, {1, 1, 3}}, 1, 2},
+	}
+	for _, tt := range tests {
+		m := NewMatrix(2, 3)
+		m.data = tt.rows
+		m.GaussianElimination()
+		if r, a := m.Rank(); r != tt.rank || a != tt.augmentedRank {
+			t.Errorf("Rank(%v) = %d, %d; want %d, %d", tt.rows, r, a, tt.rank, tt.augmentedRank)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]float64
+		want Kind
+	}{
+		{"unique", [][]float64{{2, 1, -1, 8}, {-3, -1, 2, -11}, {-2, 1, 2, -3}}, Unique},
+		{"infinite", [][]float64{{1, 1, 1, 3}, {2, 2, 2, 6}, {1, -1, 0, 0}}, Infinite},
+		{"none", [][]float64{{1, 1, 1, 3}, {1, 1, 1, 4}, {1, -1, 0, 0}}, None},
+	}
+
+	for _, tt := range tests {
+		m := NewMatrix(3, 4)
+		m.data = tt.rows
+		m.GaussianElimination()
+		if got := m.Classify(); got != tt.want {
+			t.Errorf("%s: Classify() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCoefficientRank(t *testing.T) {
+	m := NewMatrix(3, 4)
+	m.data = [][]float64{{1, 1, 1, 3}, {2, 2, 2, 6}, {3, 3, 3, 9}}
+	m.GaussianElimination()
+	if got := m.CoefficientRank(); got != 1 {
+		t.Errorf("CoefficientRank() = %d, want 1", got)
+	}
+	if free := m.cols - 1 - m.CoefficientRank(); free != 2 {
+		t.Errorf("degrees of freedom = %d, want 2", free)
+	}
+}
+
+func TestMatrixStrings(t *testing.T) {
+	m := NewMatrix(2, 3)
+	m.data = [][]float64{{1, 2, 3}, {4, 5.5, -6}}
+
+	if got, want := m.GetMatrixString(), "[1 2 | 3]\n[4 5.50 | -6]\n"; got != want {
+		t.Errorf("GetMatrixString() = %q, want %q", got, want)
+	}
+	if got, want := m.GetPlainMatrixString(), "[1 2 3]\n[4 5.50 -6]\n"; got != want {
+		t.Errorf("GetPlainMatrixString() = %q, want %q", got, want)
+	}
+
+	square := NewMatrix(3, 4)
+	square.data = [][]float64{{2, 1, -1, 8}, {-3, -1, 2, -11}, {-2, 1, 2, -3}}
+	if got, want := square.GetMatrixString(), "[2 1 -1 | 8]\n[-3 -1 2 | -11]\n[-2 1 2 | -3]\n"; got != want {
+		t.Errorf("GetMatrixString() = %q, want %q", got, want)
+	}
+}
+
+func TestParseAugmentedRows(t *testing.T) {
+	m, err := ParseAugmentedRows([]string{"1 0 | 1 2", "0, 2 | 4, 6"})
+	if err != nil {
+		t.Fatalf("ParseAugmentedRows: %v", err)
+	}
+	if m.CoefficientColumns() != 2 {
+		t.Errorf("CoefficientColumns() = %d, want 2", m.CoefficientColumns())
+	}
+	if got, want := m.GetMatrixString(), "[1 0 | 1 2]\n[0 2 | 4 6]\n"; got != want {
+		t.Errorf("GetMatrixString() = %q, want %q", got, want)
+	}
+
+	m.GaussianElimination()
+	if got, want := m.GetMatrixString(), "[1 0 | 1 2]\n[0 1 | 2 3]\n"; got != want {
+		t.Errorf("after elimination = %q, want %q", got, want)
+	}
+	if got, want := m.SolutionString(), "b1: x = 1, y = 2\nb2: x = 2, y = 3"; got != want {
+		t.Errorf("SolutionString() = %q, want %q", got, want)
+	}
+
+	dependent, _ := ParseAugmentedRows([]string{"1 1 | 2 4", "2 2 | 4 8"})
+	dependent.GaussianElimination()
+	if got, want := dependent.GeneralSolution(), "b1: x = 2 - y, y free\nb2: x = 4 - y, y free"; got != want {
+		t.Errorf("GeneralSolution() = %q, want %q", got, want)
+	}
+
+	attached, err := ParseAugmentedRows([]string{"1,1|1/2", "1,-1|1/2"})
+	if err != nil || attached.CoefficientColumns() != 2 || attached.At(0, 2) != 0.5 {
+		t.Errorf("bar without spaces and a fraction: %v, %v", attached, err)
+	}
+
+	plain, err := ParseAugmentedRows([]string{"2 1 8", "1 -1 1"})
+	if err != nil || plain.CoefficientColumns() != 2 {
+		t.Errorf("without a bar: CoefficientColumns() = %d, err = %v, want 2", plain.CoefficientColumns(), err)
+	}
+
+	for _, bad := range [][]string{
+		{"1 2 | 3", "1 | 2 3"},
+		{"1 2 | 3", "1 2"},
+		{"| 1 2"},
+		{"1 2 | 3 | 4"},
+		{"1 two | 3"},
+		{"1 2 3 |"},
+	} {
+		if _, err := ParseAugmentedRows(bad); err == nil {
+			t.Errorf("ParseAugmentedRows(%q) accepted an invalid matrix", bad)
+		}
+	}
+}
+
+func TestTolerance(t *testing.T) {
+	var def Tolerance
+	if !def.isZero(9.9e-11) || def.isZero(1e-10) || def.isZero(-1e-10) {
+		t.Errorf("default epsilon: isZero(9.9e-11) = %v, isZero(±1e-10) = %v, %v; want true, false, false",
+			def.isZero(9.9e-11), def.isZero(1e-10), def.isZero(-1e-10))
+	}
+	loose := Tolerance{Epsilon: 1e-3}
+	if !loose.isZero(9e-4) || loose.isZero(1e-3) {
+		t.Errorf("epsilon 1e-3: isZero(9e-4) = %v, isZero(1e-3) = %v; want true, false", loose.isZero(9e-4), loose.isZero(1e-3))
+	}
+
+	if got := def.round(0.1 + 0.2); got != 0.3 {
+		t.Errorf("default round(0.1 + 0.2) = %v, want 0.3", got)
+	}
+	if got := def.round(4e-11); got != 0 {
+		t.Errorf("default round(4e-11) = %v, want 0", got)
+	}
+	if got := (Tolerance{RoundDigits: 3}).round(0.12345); got != 0.123 {
+		t.Errorf("round to 3 digits = %v, want 0.123", got)
+	}
+	if got := (Tolerance{RoundDigits: -1}).round(0.1 + 0.2); got != 0.1+0.2 {
+		t.Errorf("no rounding changed 0.1 + 0.2 to %v", got)
+	}
+}
+
+func TestClone(t *testing.T) {
+	m := NewMatrix(2, 3)
+	m.data = [][]float64{{2, 1, 5}, {1, -1, 1}}
+	m.decimals = 3
+	c := m.Clone()
+	m.GaussianElimination()
+	if want := [][]float64{{2, 1, 5}, {1, -1, 1}}; !reflect.DeepEqual(c.data, want) {
+		t.Errorf("clone changed by eliminating the original: %v", c.data)
+	}
+	if c.rows != 2 || c.cols != 3 || c.decimals != 3 {
+		t.Errorf("clone = %dx%d with %d decimals", c.rows, c.cols, c.decimals)
+	}
+}
+
+func TestSwapRowsOutOfRange(t *testing.T) {
+	m := NewMatrix(2, 3)
+	m.data = [][]float64{{1, 2, 3}, {4, 5, 6}}
+	for _, rows := range [][2]int{{0, 2}, {-1, 0}} {
+		if err := m.SwapRows(rows[0], rows[1]); err == nil {
+			t.Errorf("SwapRows(%d, %d) on 2 rows did not fail", rows[0], rows[1])
+		}
+	}
+	if m.data[0][0] != 1 || m.data[1][0] != 4 {
+		t.Errorf("failed swap changed the matrix: %v", m.data)
+	}
+	if err := m.SwapRows(0, 1); err != nil || m.data[0][0] != 4 {
+		t.Errorf("SwapRows(0, 1) = %v, data %v", err, m.data)
+	}
+}
@@ -0,0 +1,320 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// variableLetters lists the classic variables in column order, see
+// systemVariables for the other names an equation may use.
+const variableLetters = "xyzwvu"
+
+var variableNames = []string{"x", "y", "z", "w", "v", "u"}
+
+func VariableName(col int) string {
+	if col < len(variableNames) {
+		return variableNames[col]
+	}
+	return fmt.Sprintf("c%d", col+1)
+}
+
+// ParseNumber parses a decimal number or a fraction such as "3/4".
+func ParseNumber(num string) (float64, error) {
+	numer, denom, isFraction := strings.Cut(num, "/")
+	v, err := strconv.ParseFloat(numer, 64)
+	if err != nil {
+		return 0, errors.New("invalid number")
+	}
+	if !isFraction {
+		return v, nil
+	}
+	d, err := strconv.ParseFloat(denom, 64)
+	if err != nil {
+		return 0, errors.New("invalid fraction")
+	}
+	if d == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return v / d, nil
+}
+
+// ParseError describes why an equation could not be parsed and where.
+type ParseError struct {
+	Equation int    // 0-based index of the equation in the system, -1 if unknown
+	Msg      string // what went wrong
+	Text     string // offending substring of the input, if any
+	Pos      int    // byte offset of Text in the input as typed
+}
+
+func (e *ParseError) Error() string {
+	msg := e.Msg
+	if e.Text != "" {
+		msg += fmt.Sprintf(": %q at position %d", e.Text, e.Pos)
+	}
+	if e.Equation >= 0 {
+		msg = fmt.Sprintf("equation %d: %s", e.Equation+1, msg)
+	}
+	return msg
+}
+
+// ParseSystem parses every equation of a system, collecting one error per
+// equation instead of stopping at the first. errs[i] is nil when equation i
+// parsed; its coeffs[i] is then valid. Errors are *ParseError values with
+// Equation set.
+//
+// Every row has one column per unknown plus the constant, and vars names
+// the unknown of each column. The unknowns are the variables the equations
+// use, however many equations there are; the columns are assigned as
+// described with systemVariables.
+func ParseSystem(equations []string) (coeffs [][]float64, vars []string, errs []error) {
+	parsed := make([]*parsedEquation, len(equations))
+	errs = make([]error, len(equations))
+	for i, eq := range equations {
+		if strings.TrimSpace(eq) == "" {
+			errs[i] = &ParseError{Equation: i, Msg: "equation is empty"}
+			continue
+		}
+		p, err := parseTerms(eq)
+		if err != nil {
+			var pe *ParseError
+			if errors.As(err, &pe) {
+				pe.Equation = i
+			}
+			errs[i] = err
+			continue
+		}
+		parsed[i] = p
+	}
+
+	vars = systemVariables(slices.DeleteFunc(slices.Clone(parsed), func(p *parsedEquation) bool {
+		return p == nil
+	}))
+	coeffs = make([][]float64, len(equations))
+	for i, p := range parsed {
+		if p != nil {
+			coeffs[i] = p.row(vars)
+		}
+	}
+	return coeffs, vars, errs
+}
+
+// CountErrors counts the non-nil entries of errs.
+func CountErrors(errs []error) int {
+	n := 0
+	for _, err := range errs {
+		if err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// normalizeEquation lowercases eq and strips spaces. positions maps each
+// byte of the normalized string back to its offset in eq, with one extra
+// entry for the end of the input.
+func normalizeEquation(eq string) (normalized string, positions []int) {
+	var b strings.Builder
+	for i := 0; i < len(eq); i++ {
+		c := eq[i]
+		if c == ' ' {
+			continue
+		}
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		b.WriteByte(c)
+		positions = append(positions, i)
+	}
+	positions = append(positions, len(eq))
+	return b.String(), positions
+}
+
+// ParseEquation parses one equation into a matrix row, with its variables
+// in columns as systemVariables would give them for this equation alone.
+func ParseEquation(input string) ([]float64, error) {
+	p, err := parseTerms(input)
+	if err != nil {
+		return nil, err
+	}
+	return p.row(systemVariables([]*parsedEquation{p})), nil
+}
+
+// parseTerms parses an equation as terms by variable name.
+func parseTerms(input string) (*parsedEquation, error) {
+	eq, positions := normalizeEquation(input)
+	p := &parsedEquation{coeffs: make(map[string]float64)}
+
+	fail := func(msg, text string, at int) error {
+		return &ParseError{Equation: -1, Msg: msg, Text: text, Pos: positions[at]}
+	}
+
+	parts := strings.Split(eq, "=")
+	if len(parts) != 2 {
+		if len(parts) > 2 {
+			return nil, fail("equation must contain exactly one '=' sign", "=", len(parts[0])+1+len(parts[1]))
+		}
+		return nil, fail("equation must contain exactly one '=' sign", "", len(eq))
+	}
+
+	// Both sides are sums of terms. Variable terms are collected on the
+	// left and constants on the right, so "2x + 3 = y + 5" becomes
+	// 2x - y = 2.
+
+	// coefficient is the signed value of a term's number, 1 if it has
+	// none; sign is the character the term starts with.
+	coefficient := func(num string, sign byte, at int) (float64, error) {
+		coeff := 1.0
+		if num != "" {
+			v, err := ParseNumber(num)
+			if err != nil {
+				return 0, fail(err.Error(), num, at)
+			}
+			coeff = v
+		}
+		if sign == '-' {
+			coeff = -coeff
+		}
+		return coeff, nil
+	}
+
+	// addTerms adds the terms of side, which starts at offset in eq,
+	// multiplied by scale. inParens is set for the inside of parentheses,
+	// which may not contain parentheses of their own.
+	var addTerms func(side string, offset int, scale float64, inParens bool) error
+	addTerms = func(side string, offset int, scale float64, inParens bool) error {
+		for i := 0; i < len(side); {
+			j := i
+			if side[j] == '+' || side[j] == '-' {
+				j++
+			}
+			numStart := j
+			for j < len(side) && (side[j] >= '0' && side[j] <= '9' || side[j] == '.' || side[j] == '/') {
+				j++
+			}
+			// An exponent, as in 1.5e-3; its sign is not an operator.
+			if j > numStart && j < len(side) && side[j] == 'e' {
+				k := j + 1
+				if k < len(side) && (side[k] == '+' || side[k] == '-') {
+					k++
+				}
+				if k < len(side) && side[k] >= '0' && side[k] <= '9' {
+					for k < len(side) && side[k] >= '0' && side[k] <= '9' {
+						k++
+					}
+					j = k
+				}
+			}
+			num := side[numStart:j]
+			// An explicit "*" may separate a coefficient from its variable
+			// or its parentheses.
+			if num != "" && j+1 < len(side) && side[j] == '*' && (isLetter(side[j+1]) || side[j+1] == '(') {
+				j++
+			}
+			// A parenthesized sum is multiplied out, so "2(x + y)" adds
+			// 2x + 2y.
+			if j < len(side) && side[j] == '(' {
+				if inParens {
+					return fail("nested parentheses are not supported", "(", offset+j)
+				}
+				end := strings.IndexByte(side[j+1:], ')')
+				if end < 0 {
+					return fail("unmatched '('", "(", offset+j)
+				}
+				if end == 0 {
+					return fail("empty parentheses", "()", offset+j)
+				}
+				coeff, err := coefficient(num, side[i], offset+numStart)
+				if err != nil {
+					return err
+				}
+				if err := addTerms(side[j+1:j+1+end], offset+j+1, scale*coeff, true); err != nil {
+					return err
+				}
+				j += end + 2
+				if j < len(side) && side[j] != '+' && side[j] != '-' {
+					return fail("invalid term", side[i:j+1], offset+i)
+				}
+				i = j
+				continue
+			}
+			// A variable is a letter with an optional subscript.
+			variable := ""
+			if j < len(side) && isLetter(side[j]) {
+				k := j + 1
+				for k < len(side) && side[k] >= '0' && side[k] <= '9' {
+					k++
+				}
+				if side[j] == 'e' {
+					return fail("e can't be a variable, it is the exponent in numbers like 1.5e-3", side[j:k], offset+j)
+				}
+				// A variable followed by a caret, another variable or a
+				// decimal point (x^2, xy, x1.5) would otherwise be split
+				// into unrelated terms and silently give a wrong
+				// coefficient.
+				if k < len(side) && (side[k] == '^' || side[k] == '.' || isLetter(side[k])) {
+					end := k
+					for end < len(side) && side[end] != '+' && side[end] != '-' {
+						end++
+					}
+					return fail("nonlinear term not supported", side[j:end], offset+j)
+				}
+				variable = side[j:k]
+				j = k
+			}
+			if j < len(side) && side[j] == ')' {
+				return fail("unmatched ')'", ")", offset+j)
+			}
+			if num == "" && variable == "" || j < len(side) && side[j] != '+' && side[j] != '-' {
+				end := len(side)
+				if k := strings.IndexAny(side[i+1:], "+-"); k >= 0 {
+					end = i + 1 + k
+				}
+				return fail("invalid term", side[i:end], offset+i)
+			}
+
+			coeff, err := coefficient(num, side[i], offset+numStart)
+			if err != nil {
+				return err
+			}
+
+			if variable != "" {
+				p.add(variable, scale*coeff)
+			} else {
+				p.constant -= scale * coeff
+			}
+			i = j
+		}
+		return nil
+	}
+
+	offset := 0
+	for s, side := range parts {
+		// A dangling operator at the end of the left side (as in
+		// "2x + y + = 5") is treated as a typo and ignored. At the end of
+		// the right side it more likely means the equation was cut short.
+		if s == 0 {
+			side = strings.TrimRight(side, "+-*")
+		}
+		if side == "" {
+			return nil, fail("missing expression on "+[]string{"left", "right"}[s]+" side", "", offset)
+		}
+		scale := 1.0
+		if s == 1 {
+			scale = -1
+		}
+		if err := addTerms(side, offset, scale, false); err != nil {
+			return nil, err
+		}
+		offset += len(parts[0]) + 1
+	}
+
+	return p, nil
+}
+
+// isLetter reports whether c is a lowercase letter.
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
@@ -0,0 +1,409 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseEquationRejectsNonlinearTerms(t *testing.T) {
+	tests := []struct {
+		eq   string
+		term string
+	}{
+		{"x^2 + y = 3", "x^2"},
+		{"2x + 3y^2 - z = 1", "y^2"},
+		{"xy + z = 4", "xy"},
+		{"x + z1.5 = 4", "z1.5"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseEquation(tt.eq)
+		if err == nil {
+			t.Errorf("ParseEquation(%q) = nil error, want nonlinear term error", tt.eq)
+			continue
+		}
+		if !strings.Contains(err.Error(), "nonlinear") || !strings.Contains(err.Error(), tt.term) {
+			t.Errorf("ParseEquation(%q) error = %q, want it to mention nonlinear term %q", tt.eq, err, tt.term)
+		}
+	}
+}
+
+func TestParseEquationAcceptsLinearTerms(t *testing.T) {
+	coeffs, err := ParseEquation("2x + y - z = 8")
+	if err != nil {
+		t.Fatalf("ParseEquation returned error: %v", err)
+	}
+	want := []float64{2, 1, -1, 8}
+	for i := range want {
+		if coeffs[i] != want[i] {
+			t.Fatalf("coeffs = %v, want %v", coeffs, want)
+		}
+	}
+}
+
+func TestParseEquation(t *testing.T) {
+	tests := []struct {
+		eq      string
+		want    []float64
+		wantErr bool
+	}{
+		{eq: "x=1", want: []float64{1, 1}},
+		{eq: "-2x+y=3", want: []float64{-2, 1, 3}},
+		{eq: "-x - y - z = -1", want: []float64{-1, -1, -1, -1}},
+		{eq: "3x + 2y - 4z = 10", want: []float64{3, 2, -4, 10}},
+		{eq: "z = 4", want: []float64{0, 0, 1, 4}},
+		{eq: "2x + 3z = 1", want: []float64{2, 0, 3, 1}},
+		{eq: "2x + y", wantErr: true},
+		{eq: "x = 1 = 2", wantErr: true},
+		{eq: "x = 1.2.3", wantErr: true},
+		{eq: "x = 1e", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseEquation(tt.eq)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseEquation(%q) = %v, want an error", tt.eq, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseEquation(%q) returned error: %v", tt.eq, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseEquation(%q) = %v, want %v", tt.eq, got, tt.want)
+		}
+	}
+}
+
+func TestParseEquationReturnsParseError(t *testing.T) {
+	tests := []struct {
+		eq   string
+		text string
+		pos  int
+	}{
+		{"2x + y = abc", "abc", 9},
+		{"x + y = 1 = 2", "=", 10},
+		{"x + y", "", 5},
+		{"X + Y^2 = 3", "y^2", 4},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseEquation(tt.eq)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("ParseEquation(%q) error = %v, want *ParseError", tt.eq, err)
+			continue
+		}
+		if pe.Text != tt.text || pe.Pos != tt.pos || pe.Equation != -1 {
+			t.Errorf("ParseEquation(%q) = %+v, want Text %q at Pos %d", tt.eq, pe, tt.text, tt.pos)
+		}
+	}
+
+	pe := &ParseError{Equation: 1, Msg: "invalid constant on right side", Text: "abc", Pos: 9}
+	if got, want := pe.Error(), `equation 2: invalid constant on right side: "abc" at position 9`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestParseEquationIgnoresTrailingOperator(t *testing.T) {
+	for _, eq := range []string{"2x + y + = 5", "2x + y - = 5", "2x + y * = 5", "2x+y+=5"} {
+		coeffs, err := ParseEquation(eq)
+		if err != nil {
+			t.Errorf("ParseEquation(%q) returned error: %v", eq, err)
+			continue
+		}
+		want := []float64{2, 1, 5}
+		if len(coeffs) != len(want) {
+			t.Errorf("ParseEquation(%q) = %v, want %v", eq, coeffs, want)
+			continue
+		}
+		for i := range want {
+			if coeffs[i] != want[i] {
+				t.Errorf("ParseEquation(%q) = %v, want %v", eq, coeffs, want)
+				break
+			}
+		}
+	}
+
+	for _, eq := range []string{"x = 5 -", "x = 5+", "2x + y = 5 *"} {
+		if coeffs, err := ParseEquation(eq); err == nil {
+			t.Errorf("ParseEquation(%q) = %v, want an error for the truncated right side", eq, coeffs)
+		}
+	}
+}
+
+func TestParseSystemCollectsAllErrors(t *testing.T) {
+	coeffs, _, errs := ParseSystem([]string{"2x + y = 3", "x + y", "", "x - z^2 = 1"})
+	if len(coeffs) != 4 || len(errs) != 4 {
+		t.Fatalf("got %d coeffs and %d errors, want 4 of each", len(coeffs), len(errs))
+	}
+	if errs[0] != nil || coeffs[0][0] != 2 || coeffs[0][2] != 3 {
+		t.Errorf("equation 1: coeffs %v, err %v", coeffs[0], errs[0])
+	}
+	for i := 1; i < 4; i++ {
+		var pe *ParseError
+		if !errors.As(errs[i], &pe) || pe.Equation != i {
+			t.Errorf("errs[%d] = %v, want a *ParseError for equation %d", i, errs[i], i)
+		}
+		if coeffs[i] != nil {
+			t.Errorf("coeffs[%d] = %v, want nil for a failed equation", i, coeffs[i])
+		}
+	}
+	if n := CountErrors(errs); n != 3 {
+		t.Errorf("CountErrors = %d, want 3", n)
+	}
+}
+
+func TestParseSystemSizesRowsToUnknowns(t *testing.T) {
+	coeffs, _, _ := ParseSystem([]string{"x = 1", "y = 2"})
+	if len(coeffs[0]) != 3 {
+		t.Errorf("2-equation row %v, want 3 columns", coeffs[0])
+	}
+	coeffs, _, _ = ParseSystem([]string{"x + w = 1", "y = 2"})
+	if len(coeffs[1]) != 5 || coeffs[1][4] != 2 {
+		t.Errorf("row %v, want 4 unknowns with the constant last", coeffs[1])
+	}
+}
+
+func TestParseEquationBothSides(t *testing.T) {
+	tests := []struct {
+		eq   string
+		want []float64
+	}{
+		{"2x + 3 = y + 5", []float64{2, -1, 2}},
+		{"x = 2y - 1", []float64{1, -2, -1}},
+		{"x + y + 1 = x + 4", []float64{0, 1, 3}},
+		{"3 = z", []float64{0, 0, -1, -3}},
+	}
+	for _, tt := range tests {
+		got, err := ParseEquation(tt.eq)
+		if err != nil {
+			t.Errorf("ParseEquation(%q) returned error: %v", tt.eq, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("ParseEquation(%q) = %v, want %v", tt.eq, got, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("ParseEquation(%q) = %v, want %v", tt.eq, got, tt.want)
+				break
+			}
+		}
+	}
+
+	for _, bad := range []string{"x = ", "= 2", "x ++ y = 2", "2x + 3e = 1"} {
+		if _, err := ParseEquation(bad); err == nil {
+			t.Errorf("ParseEquation(%q) accepted an invalid equation", bad)
+		}
+	}
+}
+
+func TestParseEquationConstantsAmongTerms(t *testing.T) {
+	// Constants move to the right-hand side and variables to the left
+	// wherever they appear among the terms.
+	tests := []struct {
+		eq   string
+		want []float64
+	}{
+		{"5 + 2x = y", []float64{2, -1, -5}},
+		{"5 + 2x + y = 8", []float64{2, 1, 3}},
+		{"2x + 5 + y = 8", []float64{2, 1, 3}},
+		{"2x + y + 5 = 8", []float64{2, 1, 3}},
+		{"-5 + 2x + y = -2", []float64{2, 1, 3}},
+		{"8 = 5 + 2x + y", []float64{-2, -1, -3}},
+		{"2x = 1.5 - y + 1.5", []float64{2, 1, 3}},
+		{"1.5 + 2x = 4.5 - y", []float64{2, 1, 3}},
+		{"2x + 0.5 = 3.5 - y", []float64{2, 1, 3}},
+	}
+	for _, tt := range tests {
+		got, err := ParseEquation(tt.eq)
+		if err != nil {
+			t.Errorf("ParseEquation(%q) returned error: %v", tt.eq, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParseEquation(%q) = %v, want %v", tt.eq, got, tt.want)
+		}
+	}
+
+	solution, _, err := Solve([]string{"5 + 2x = y", "x + y = 8"})
+	if err != nil || solution["x"] != 1 || solution["y"] != 7 {
+		t.Errorf("Solve with a leading constant = %v, %v, want x = 1, y = 7", solution, err)
+	}
+}
+
+func TestParseEquationCoefficientForms(t *testing.T) {
+	for _, eq := range []string{"3*x = 6", "3 * x = 6", "3x = 6", "3 x = 6"} {
+		got, err := ParseEquation(eq)
+		if err != nil || len(got) != 2 || got[0] != 3 || got[1] != 6 {
+			t.Errorf("ParseEquation(%q) = %v, %v; want [3 6]", eq, got, err)
+		}
+	}
+
+	got, err := ParseEquation("x - y + z = 2")
+	if err != nil || len(got) != 4 || got[0] != 1 || got[1] != -1 || got[2] != 1 {
+		t.Errorf("ParseEquation(%q) = %v, %v; want implicit coefficients 1, -1, 1", "x - y + z = 2", got, err)
+	}
+
+	for _, bad := range []string{"*x = 1", "2** x = 1", "2 * 3 = x"} {
+		if _, err := ParseEquation(bad); err == nil {
+			t.Errorf("ParseEquation(%q) accepted an invalid equation", bad)
+		}
+	}
+}
+
+func TestParseEquationRejectsE(t *testing.T) {
+	tests := []struct {
+		eq   string
+		text string
+		pos  int
+	}{
+		{"2e + b = 3", "e", 1},
+		{"x + e1 = 1", "e1", 4},
+		{"x + y = 3e", "e", 9},
+	}
+	for _, tt := range tests {
+		_, err := ParseEquation(tt.eq)
+		var pe *ParseError
+		if !errors.As(err, &pe) || !strings.HasPrefix(pe.Msg, "e can't be a variable") || pe.Text != tt.text || pe.Pos != tt.pos {
+			t.Errorf("ParseEquation(%q) error = %v, want e rejected as %q at %d", tt.eq, err, tt.text, tt.pos)
+		}
+	}
+
+	if got, err := ParseEquation("x = 1.5e-3"); err != nil || len(got) != 2 || got[1] != 1.5e-3 {
+		t.Errorf("ParseEquation(%q) = %v, %v; want the exponent read as part of the number", "x = 1.5e-3", got, err)
+	}
+}
+
+func TestParseEquationScientificNotation(t *testing.T) {
+	tests := []struct {
+		eq   string
+		want []float64
+	}{
+		{"1.5e-3x + 2E4y = 1e2", []float64{1.5e-3, 2e4, 100}},
+		{"2e+3x - 4e-2 = 0", []float64{2000, 0.04}},
+		{"-3E2z = -1.25e-5", []float64{0, 0, -300, -1.25e-5}},
+	}
+	for _, tt := range tests {
+		got, err := ParseEquation(tt.eq)
+		if err != nil {
+			t.Errorf("ParseEquation(%q) returned error: %v", tt.eq, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("ParseEquation(%q) = %v, want %v", tt.eq, got, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if math.Abs(got[i]-tt.want[i]) > 1e-12*math.Max(1, math.Abs(tt.want[i])) {
+				t.Errorf("ParseEquation(%q) = %v, want %v", tt.eq, got, tt.want)
+				break
+			}
+		}
+	}
+
+	for _, bad := range []string{"2ex = 1", "2e-x = 1", "e3x = 1", "x = 1e"} {
+		if _, err := ParseEquation(bad); err == nil {
+			t.Errorf("ParseEquation(%q) accepted an invalid equation", bad)
+		}
+	}
+}
+
+func TestParseEquationFractions(t *testing.T) {
+	got, err := ParseEquation("1/2x + 3/4y = 5/2")
+	if err != nil {
+		t.Fatalf("ParseEquation returned error: %v", err)
+	}
+	want := []float64{0.5, 0.75, 2.5}
+	for i := range want {
+		if len(got) != len(want) || got[i] != want[i] {
+			t.Fatalf("coeffs = %v, want %v", got, want)
+		}
+	}
+
+	for _, bad := range []string{"1/0x = 1", "1/x = 2", "1/2/3x = 1"} {
+		if _, err := ParseEquation(bad); err == nil {
+			t.Errorf("ParseEquation(%q) accepted an invalid fraction", bad)
+		}
+	}
+}
+
+func TestParseEquationSignsAndDecimalPoints(t *testing.T) {
+	tests := []struct {
+		eq   string
+		want []float64
+	}{
+		{"-x = 1", []float64{-1, 1}},
+		{"+x = 1", []float64{1, 1}},
+		{"-.5x = 1", []float64{-0.5, 1}},
+		{"+.5x = +1", []float64{0.5, 1}},
+		{"3.x = 1", []float64{3, 1}},
+		{"x - y = 3.", []float64{1, -1, 3}},
+		{"-y = -.25", []float64{0, -1, -0.25}},
+	}
+	for _, tt := range tests {
+		got, err := ParseEquation(tt.eq)
+		if err != nil {
+			t.Errorf("ParseEquation(%q) returned error: %v", tt.eq, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParseEquation(%q) = %v, want %v", tt.eq, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"-.x = 1", "x = -.", "x + -y = 1", "x = 1..5"} {
+		if _, err := ParseEquation(bad); err == nil {
+			t.Errorf("ParseEquation(%q) accepted an invalid equation", bad)
+		}
+	}
+}
+
+func TestParseEquationParentheses(t *testing.T) {
+	tests := []struct {
+		eq   string
+		want []float64
+	}{
+		{"2(x + y) - z = 4", []float64{2, 2, -1, 4}},
+		{"-(x - y) = 1", []float64{-1, 1, 1}},
+		{"3*(x - 2) = y", []float64{3, -1, 6}},
+		{"x = 1/2(4y + 2)", []float64{1, -2, 1}},
+		{"(x + y) + 2(y - 1) = 0", []float64{1, 3, 2}},
+	}
+	for _, tt := range tests {
+		got, err := ParseEquation(tt.eq)
+		if err != nil {
+			t.Errorf("ParseEquation(%q) returned error: %v", tt.eq, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParseEquation(%q) = %v, want %v", tt.eq, got, tt.want)
+		}
+	}
+
+	bad := []struct {
+		eq  string
+		msg string
+		pos int
+	}{
+		{"2(x + (y)) = 1", "nested parentheses are not supported", 6},
+		{"2(x + y = 1", "unmatched '('", 1},
+		{"2x + y) = 1", "unmatched ')'", 6},
+		{"2() = 1", "empty parentheses", 1},
+		{"(x + y)z = 1", "invalid term", 0},
+	}
+	for _, tt := range bad {
+		_, err := ParseEquation(tt.eq)
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Msg != tt.msg || pe.Pos != tt.pos {
+			t.Errorf("ParseEquation(%q) error = %v, want %q at %d", tt.eq, err, tt.msg, tt.pos)
+		}
+	}
+}
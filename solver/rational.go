@@ -0,0 +1,262 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// RatMatrix is the exact counterpart of Matrix: its entries are rationals,
+// so the elimination never rounds and 1/3 stays 1/3 instead of 0.33.
+type RatMatrix struct {
+	rows      int
+	cols      int
+	data      [][]*big.Rat
+	trace     []Step
+	labels    RowLabels
+	names     []string // variable of each column, see Matrix.VariableName
+	snapshots bool     // keep Step.After in the trace, see Matrix.SetSnapshots
+}
+
+func NewRatMatrix(rows, cols int) *RatMatrix {
+	data := make([][]*big.Rat, rows)
+	for i := range data {
+		data[i] = make([]*big.Rat, cols)
+		for j := range data[i] {
+			data[i][j] = new(big.Rat)
+		}
+	}
+	return &RatMatrix{rows: rows, cols: cols, data: data}
+}
+
+// ExactMatrix converts m to a RatMatrix. Each entry becomes the simplest
+// fraction with the same float64 value, so coefficients typed as 0.1 or 1/3
+// come out as exactly 1/10 and 1/3.
+func ExactMatrix(m *Matrix) *RatMatrix {
+	r := NewRatMatrix(m.rows, m.cols)
+	r.labels = m.labels
+	r.names = m.names
+	r.snapshots = m.snapshots
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			r.data[i][j] = ratFromFloat(m.data[i][j])
+		}
+	}
+	return r
+}
+
+// ratFromFloat returns the continued-fraction convergent of v with the
+// smallest denominator that converts back to v, or v's exact binary value
+// if no convergent with a denominator up to a million does.
+func ratFromFloat(v float64) *big.Rat {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return new(big.Rat)
+	}
+	p, q, ok := convergent(v, 1e6, func(p, q int64) bool {
+		return float64(p)/float64(q) == v
+	})
+	if ok {
+		return big.NewRat(p, q)
+	}
+	return new(big.Rat).SetFloat64(v)
+}
+
+// convergent returns the first convergent p/q of v's continued fraction
+// that done accepts, or false once the denominator passes maxDenominator
+// or the fraction ends first.
+func convergent(v float64, maxDenominator int64, done func(p, q int64) bool) (p, q int64, ok bool) {
+	// h and k are the numerators and denominators of the last two
+	// convergents.
+	h0, h1 := int64(0), int64(1)
+	k0, k1 := int64(1), int64(0)
+	x := v
+	for {
+		a := math.Floor(x)
+		if math.Abs(a) > 1e15 {
+			return 0, 0, false
+		}
+		h0, h1 = h1, int64(a)*h1+h0
+		k0, k1 = k1, int64(a)*k1+k0
+		if k1 > maxDenominator {
+			return 0, 0, false
+		}
+		if done(h1, k1) {
+			return h1, k1, true
+		}
+		if x == a {
+			return 0, 0, false
+		}
+		x = 1 / (x - a)
+	}
+}
+
+func (m *RatMatrix) SwapRows(i, j int) {
+	m.data[i], m.data[j] = m.data[j], m.data[i]
+}
+
+func (m *RatMatrix) MultiplyRow(row int, scalar *big.Rat) {
+	for j := 0; j < m.cols; j++ {
+		m.data[row][j].Mul(m.data[row][j], scalar)
+	}
+}
+
+func (m *RatMatrix) AddMultipleOfRow(target, source int, scalar *big.Rat) {
+	term := new(big.Rat)
+	for j := 0; j < m.cols; j++ {
+		term.Mul(m.data[source][j], scalar)
+		m.data[target][j].Add(m.data[target][j], term)
+	}
+}
+
+// GaussianElimination reduces m to reduced row echelon form like
+// Matrix.GaussianElimination, with the scalars in the steps written as
+// fractions. Exact arithmetic has no rounding to keep small, so the first
+// non-zero entry is taken as the pivot.
+func (m *RatMatrix) GaussianElimination() []string {
+	steps := []string{}
+	m.trace = nil
+	pivotRow := -1
+	addStep := func(column int, format string, args ...any) {
+		steps = append(steps, fmt.Sprintf(format, args...))
+		m.trace = append(m.trace, Step{Column: column, PivotRow: pivotRow, After: m.snapshot()})
+	}
+
+	n := m.cols - 1
+	lead := 0
+	addStep(-1, "Starting Gaussian Elimination...")
+
+	for r := 0; r < m.rows && lead < m.cols; r++ {
+		pivotRow = -1
+		i := m.firstNonZeroRow(lead, r)
+		for i < 0 {
+			if lead < n {
+				addStep(lead, "Singular at step %d: no non-zero pivot in column %d (%s)",
+					r+1, lead+1, m.VariableName(lead))
+			}
+			lead++
+			if lead == m.cols {
+				return steps
+			}
+			i = m.firstNonZeroRow(lead, r)
+		}
+
+		pivotRow = r
+		if i != r {
+			m.SwapRows(i, r)
+			addStep(lead, "%s ↔ %s", m.labels.label(i), m.labels.label(r))
+		}
+
+		if pivot := m.data[r][lead]; pivot.Cmp(big.NewRat(1, 1)) != 0 {
+			scalar := new(big.Rat).Inv(pivot)
+			m.MultiplyRow(r, scalar)
+			addStep(lead, "%s → %s%s", m.labels.label(r), scalar.RatString(), m.labels.label(r))
+		}
+
+		for i := 0; i < m.rows; i++ {
+			if i == r || m.data[i][lead].Sign() == 0 {
+				continue
+			}
+			scalar := new(big.Rat).Neg(m.data[i][lead])
+			m.AddMultipleOfRow(i, r, scalar)
+			addStep(lead, "%s + %s%s → %s", m.labels.label(i), scalar.RatString(), m.labels.label(r), m.labels.label(i))
+		}
+
+		lead++
+	}
+
+	return steps
+}
+
+// Trace returns one Step per entry of the step list of the last
+// GaussianElimination, like Matrix.Trace.
+func (m *RatMatrix) Trace() []Step {
+	return m.trace
+}
+
+// snapshot returns the entries a Step keeps, like Matrix.snapshot.
+func (m *RatMatrix) snapshot() [][]float64 {
+	if !m.snapshots {
+		return nil
+	}
+	return m.floatData()
+}
+
+// floatData returns the entries rounded to float64, for drawing.
+func (m *RatMatrix) floatData() [][]float64 {
+	data := make([][]float64, m.rows)
+	for i := range data {
+		data[i] = make([]float64, m.cols)
+		for j := range data[i] {
+			data[i][j], _ = m.data[i][j].Float64()
+		}
+	}
+	return data
+}
+
+func (m *RatMatrix) firstNonZeroRow(col, startRow int) int {
+	for i := startRow; i < m.rows; i++ {
+		if m.data[i][col].Sign() != 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// Classify is Matrix.Classify for a reduced RatMatrix, with exact zero
+// tests.
+func (m *RatMatrix) Classify() Kind {
+	n := m.cols - 1
+	rank := 0
+	for i := 0; i < m.rows; i++ {
+		zeroRow := true
+		for j := 0; j < n; j++ {
+			if m.data[i][j].Sign() != 0 {
+				zeroRow = false
+				break
+			}
+		}
+		if !zeroRow {
+			rank++
+			continue
+		}
+		if m.data[i][n].Sign() != 0 {
+			return None
+		}
+	}
+	if rank < n {
+		return Infinite
+	}
+	return Unique
+}
+
+// SolutionString formats the unique solution of a reduced matrix as
+// "x = 1/3, y = 2, ...".
+func (m *RatMatrix) SolutionString() string {
+	last := m.cols - 1
+	values := make([]string, last)
+	for i := range values {
+		values[i] = fmt.Sprintf("%s = %s", m.VariableName(i), m.data[i][last].RatString())
+	}
+	return strings.Join(values, ", ")
+}
+
+// GetMatrixString renders the augmented matrix with exact entries, e.g.
+// "[1 0 | 1/3]".
+func (m *RatMatrix) GetMatrixString() string {
+	var result strings.Builder
+	for i := 0; i < m.rows; i++ {
+		result.WriteString("[")
+		for j := 0; j < m.cols; j++ {
+			if j > 0 {
+				result.WriteString(" ")
+			}
+			if j == m.cols-1 && j > 0 {
+				result.WriteString("| ")
+			}
+			result.WriteString(m.data[i][j].RatString())
+		}
+		result.WriteString("]\n")
+	}
+	return result.String()
+}
@@ -0,0 +1,66 @@
+package solver
+
+import (
+	"testing"
+)
+
+func TestRatFromFloat(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{2, "2"},
+		{-1.0 / 3, "-1/3"},
+		{0.1, "1/10"},
+		{2.5, "5/2"},
+		{3.0 / 7, "3/7"},
+	}
+	for _, tt := range tests {
+		if got := ratFromFloat(tt.v).RatString(); got != tt.want {
+			t.Errorf("ratFromFloat(%v) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestRatMatrixElimination(t *testing.T) {
+	m, errs := Parse([]string{"3x + y = 1", "x - y = 0"})
+	if m == nil {
+		t.Fatalf("Parse: %v", errs)
+	}
+	r := ExactMatrix(m)
+	steps := r.GaussianElimination()
+	if len(steps) != len(r.trace) {
+		t.Errorf("%d steps but %d trace entries", len(steps), len(r.trace))
+	}
+	if steps[1] != "L1 → 1/3L1" {
+		t.Errorf("first operation = %q, want %q", steps[1], "L1 → 1/3L1")
+	}
+	if kind := r.Classify(); kind != Unique {
+		t.Fatalf("classified as %v, want unique", kind)
+	}
+	if got, want := r.SolutionString(), "x = 1/4, y = 1/4"; got != want {
+		t.Errorf("solution = %q, want %q", got, want)
+	}
+	if got, want := r.GetMatrixString(), "[1 0 | 1/4]\n[0 1 | 1/4]\n"; got != want {
+		t.Errorf("matrix =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRatMatrixClassify(t *testing.T) {
+	tests := []struct {
+		equations []string
+		want      Kind
+	}{
+		{[]string{"x + y = 1", "x + y = 2"}, None},
+		{[]string{"x + y = 1/3", "3x + 3y = 1"}, Infinite},
+	}
+	for _, tt := range tests {
+		m, _ := Parse(tt.equations)
+		r := ExactMatrix(m)
+		r.GaussianElimination()
+		if kind := r.Classify(); kind != tt.want {
+			t.Errorf("%q: classified as %v, want %v", tt.equations, kind, tt.want)
+		}
+	}
+}
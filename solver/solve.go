@@ -0,0 +1,234 @@
+// Package solver parses systems of linear equations and solves them by
+// Gaussian elimination, recording every row operation as a step. It also
+// offers LU decomposition, Cramer's rule, the Jacobi and Gauss-Seidel
+// iterations and exact rational elimination. It has no GUI dependencies;
+// package main builds the window and the command-line modes on top of it.
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors Solve returns for systems without a unique solution.
+var (
+	ErrNoSolution        = errors.New("no solution (inconsistent system)")
+	ErrInfiniteSolutions = errors.New("infinitely many solutions")
+)
+
+// Solve solves a system of linear equations without any window or
+// file output. It returns the value of every unknown by name ("x", "y",
+// ...) and the elimination steps. A parse failure is reported for every
+// bad equation; a system without a unique solution returns its steps
+// together with ErrNoSolution or ErrInfiniteSolutions.
+func Solve(equations []string) (solution map[string]float64, steps []string, err error) {
+	if len(equations) == 0 {
+		return nil, nil, errors.New("no equations given")
+	}
+	m, errs := Parse(equations)
+	if m == nil {
+		return nil, nil, errors.Join(errs...)
+	}
+	return m.Solve()
+}
+
+// Parse parses a system into its augmented matrix. The matrix is nil
+// when any equation fails to parse.
+func Parse(equations []string) (*Matrix, []error) {
+	coeffs, vars, errs := ParseSystem(equations)
+	if len(coeffs) == 0 || CountErrors(errs) > 0 {
+		return nil, errs
+	}
+	m := NewMatrix(len(coeffs), len(coeffs[0]))
+	copy(m.data, coeffs)
+	m.names = vars
+	return m, errs
+}
+
+// Solve runs the elimination on m and reads off the solution, returning
+// the same results as the package-level Solve.
+func (m *Matrix) Solve() (map[string]float64, []string, error) {
+	steps := m.GaussianElimination()
+	if m.anomaly != "" {
+		return nil, steps, errors.New("elimination halted, " + m.anomaly)
+	}
+	switch m.Classify() {
+	case None:
+		return nil, steps, ErrNoSolution
+	case Infinite:
+		return nil, steps, ErrInfiniteSolutions
+	}
+	return m.SolutionValues(), steps, nil
+}
+
+// SolutionVector returns the values of the unknowns of a reduced matrix
+// with a unique solution, in column order.
+func (m *Matrix) SolutionVector() []float64 {
+	x := make([]float64, m.CoefficientColumns())
+	for i := range x {
+		x[i] = m.data[i][m.cols-1]
+	}
+	return x
+}
+
+// SolutionValues maps each unknown of a reduced matrix with a unique
+// solution to its value.
+func (m *Matrix) SolutionValues() map[string]float64 {
+	last := m.cols - 1
+	solution := make(map[string]float64, last)
+	for i := 0; i < m.CoefficientColumns(); i++ {
+		solution[m.VariableName(i)] = m.data[i][last]
+	}
+	return solution
+}
+
+// Residuals returns Ax - b for each row of the augmented matrix data, the
+// amount by which x misses each equation.
+func Residuals(data [][]float64, x []float64) []float64 {
+	r := make([]float64, len(data))
+	for i, row := range data {
+		last := len(row) - 1
+		for j, v := range row[:last] {
+			r[i] += v * x[j]
+		}
+		r[i] -= row[last]
+	}
+	return r
+}
+
+// SolutionString formats the unique solution of a reduced matrix as
+// "x = 2, y = 1/3, z = 0.14, ...", one entry per unknown, with simple
+// fractions written as fractions. With several right-hand sides there is
+// one line per column, e.g. "b1: x = 1, y = 2".
+func (m *Matrix) SolutionString() string {
+	return m.eachRightHandSide(func(col int) string {
+		x := make([]float64, m.CoefficientColumns())
+		for i := range x {
+			x[i] = m.data[i][col]
+		}
+		return m.ValuesString(x)
+	})
+}
+
+// eachRightHandSide returns describe(col) for the right-hand side column
+// col, or one line "b1: ...", "b2: ..." per column if there are several.
+func (m *Matrix) eachRightHandSide(describe func(col int) string) string {
+	n := m.CoefficientColumns()
+	if n == m.cols-1 {
+		return describe(n)
+	}
+	lines := make([]string, m.cols-n)
+	for k := range lines {
+		lines[k] = fmt.Sprintf("b%d: %s", k+1, describe(n+k))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValuesString writes one value per unknown of m like SolutionString, for
+// values found some other way, such as by Cramer's rule.
+func (m *Matrix) ValuesString(x []float64) string {
+	values := make([]string, len(x))
+	for i, v := range x {
+		values[i] = fmt.Sprintf("%s = %s", m.VariableName(i), FormatValue(v, m.decimals))
+	}
+	return strings.Join(values, ", ")
+}
+
+// GeneralSolution expresses every unknown of a reduced, consistent matrix
+// in terms of the free ones, e.g. "x = 2.00 - 0.50z, y = 1.00 + z, z free".
+func (m *Matrix) GeneralSolution() string {
+	return m.eachRightHandSide(func(col int) string { return m.solutionInTerms(false, col) })
+}
+
+// ParametricSolution is GeneralSolution with the free variables replaced
+// by parameters, t or t1, t2, ... if there are several, e.g.
+// "x = 2 - t, y = t, z = 3".
+func (m *Matrix) ParametricSolution() string {
+	return m.eachRightHandSide(func(col int) string { return m.solutionInTerms(true, col) })
+}
+
+// solutionInTerms writes the general or parametric solution for the
+// right-hand side in column rhs.
+func (m *Matrix) solutionInTerms(parametric bool, rhs int) string {
+	n := m.CoefficientColumns()
+	pivotRow := m.pivotRows()
+
+	// names[j] is what column j is written as on the right-hand side.
+	names := make([]string, n)
+	free := 0
+	for j := range names {
+		names[j] = m.VariableName(j)
+		if pivotRow[j] < 0 {
+			free++
+		}
+	}
+	if parametric {
+		k := 0
+		for j := range names {
+			if pivotRow[j] >= 0 {
+				continue
+			}
+			k++
+			names[j] = "t"
+			if free > 1 {
+				names[j] = fmt.Sprintf("t%d", k)
+			}
+		}
+	}
+
+	parts := make([]string, n)
+	for j := range parts {
+		r := pivotRow[j]
+		if r < 0 {
+			if parametric {
+				parts[j] = m.VariableName(j) + " = " + names[j]
+			} else {
+				parts[j] = m.VariableName(j) + " free"
+			}
+			continue
+		}
+		// A zero constant is left out unless nothing else is written.
+		expr := ""
+		if !m.tol.isZero(m.data[r][rhs]) {
+			expr = FormatNumber(m.data[r][rhs], m.decimals)
+		}
+		for k := j + 1; k < n; k++ {
+			if pivotRow[k] >= 0 || m.tol.isZero(m.data[r][k]) {
+				continue
+			}
+			coeff := -m.data[r][k]
+			sign := "+"
+			if coeff < 0 {
+				sign, coeff = "-", -coeff
+			}
+			term := names[k]
+			if coeff != 1 {
+				term = FormatNumber(coeff, m.decimals) + names[k]
+			}
+			switch {
+			case expr != "":
+				expr += " " + sign + " " + term
+			case sign == "-":
+				expr = "-" + term
+			default:
+				expr = term
+			}
+		}
+		if expr == "" {
+			expr = FormatNumber(m.data[r][rhs], m.decimals)
+		}
+		parts[j] = m.VariableName(j) + " = " + expr
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (k Kind) String() string {
+	switch k {
+	case Infinite:
+		return "infinite"
+	case None:
+		return "none"
+	}
+	return "unique"
+}
@@ -0,0 +1,113 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSolveSystemsOfOtherSizes(t *testing.T) {
+	tests := []struct {
+		equations []string
+		want      string
+	}{
+		{[]string{"x + y = 3", "x - y = 1"}, "x = 2, y = 1"},
+		{[]string{"x + w = 5", "y + w = 6", "z + w = 7", "x + y + z + w = 14"}, "x = 3, y = 4, z = 5, w = 2"},
+		// More equations than unknowns, consistent, so no unknown is free.
+		{[]string{"x + y = 3", "x - y = 1", "2x + y = 5"}, "x = 2, y = 1"},
+		{[]string{"0 = 0", "x = 1"}, "x = 1"},
+	}
+	for _, tt := range tests {
+		coeffs, _, errs := ParseSystem(tt.equations)
+		if n := CountErrors(errs); n > 0 {
+			t.Fatalf("ParseSystem(%q): %d errors", tt.equations, n)
+		}
+		m := NewMatrix(len(coeffs), len(coeffs[0]))
+		copy(m.data, coeffs)
+		m.GaussianElimination()
+		if kind := m.Classify(); kind != Unique {
+			t.Errorf("%q: classified as %v, want unique", tt.equations, kind)
+			continue
+		}
+		if got := m.SolutionString(); got != tt.want {
+			t.Errorf("%q: solution %q, want %q", tt.equations, got, tt.want)
+		}
+	}
+}
+
+func TestSolveSystem(t *testing.T) {
+	solution, steps, err := Solve([]string{"2x + y - z = 8", "-3x - y + 2z = -11", "-2x + y + 2z = -3"})
+	if err != nil {
+		t.Fatalf("Solve: %v", err)
+	}
+	want := map[string]float64{"x": 2, "y": 3, "z": -1}
+	for name, v := range want {
+		if math.Abs(solution[name]-v) > 1e-9 {
+			t.Errorf("%s = %v, want %v", name, solution[name], v)
+		}
+	}
+	if len(steps) == 0 {
+		t.Error("Solve returned no steps")
+	}
+
+	tests := []struct {
+		equations []string
+		want      error
+	}{
+		{[]string{"x + y = 1", "x + y = 2"}, ErrNoSolution},
+		{[]string{"x + y = 1", "2x + 2y = 2"}, ErrInfiniteSolutions},
+	}
+	for _, tt := range tests {
+		if _, _, err := Solve(tt.equations); !errors.Is(err, tt.want) {
+			t.Errorf("Solve(%q) error = %v, want %v", tt.equations, err, tt.want)
+		}
+	}
+
+	_, _, err = Solve([]string{"x + y = 1", "x ++ y"})
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Equation != 1 {
+		t.Errorf("Solve with a bad equation: error = %v, want a *ParseError for equation 1", err)
+	}
+}
+
+func TestGeneralSolution(t *testing.T) {
+	tests := []struct {
+		equations  []string
+		want       string
+		parametric string
+	}{
+		{[]string{"x + y = 3", "2x + 2y = 6"}, "x = 3 - y, y free", "x = 3 - t, y = t"},
+		{[]string{"x + 2z = 4", "y - z = 1", "x + y + z = 5"}, "x = 4 - 2z, y = 1 + z, z free", "x = 4 - 2t, y = 1 + t, z = t"},
+		{[]string{"x + y - z = 2", "2x + 2y - 2z = 4", "3x + 3y - 3z = 6"}, "x = 2 - y + z, y free, z free", "x = 2 - t1 + t2, y = t1, z = t2"},
+		{[]string{"x + y + z = 0", "2x + 2y + 2z = 0", "x - y = 0"}, "x = -0.50z, y = -0.50z, z free", "x = -0.50t, y = -0.50t, z = t"},
+		{[]string{"x - y = 0", "2x - 2y = 0"}, "x = y, y free", "x = t, y = t"},
+	}
+	for _, tt := range tests {
+		m, errs := Parse(tt.equations)
+		if m == nil {
+			t.Fatalf("Parse(%q): %v", tt.equations, errors.Join(errs...))
+		}
+		m.GaussianElimination()
+		if kind := m.Classify(); kind != Infinite {
+			t.Errorf("%q: classified as %v, want infinite", tt.equations, kind)
+			continue
+		}
+		if got := m.GeneralSolution(); got != tt.want {
+			t.Errorf("%q: general solution %q, want %q", tt.equations, got, tt.want)
+		}
+		if got := m.ParametricSolution(); got != tt.parametric {
+			t.Errorf("%q: parametric solution %q, want %q", tt.equations, got, tt.parametric)
+		}
+	}
+}
+
+func TestResiduals(t *testing.T) {
+	data := [][]float64{{2, 1, 5}, {1, -1, 1}}
+	if got, want := Residuals(data, []float64{2, 1}), []float64{0, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("residuals of the solution = %v, want %v", got, want)
+	}
+	if got, want := Residuals(data, []float64{2, 2}), []float64{1, -1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("residuals of a wrong answer = %v, want %v", got, want)
+	}
+}
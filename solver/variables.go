@@ -0,0 +1,129 @@
+package solver
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// An equation names its unknowns with a letter, optionally followed by a
+// subscript: x, y, a, b, x1, x2, ... ("e" is left for exponents such as
+// 1.5e-3). The columns of the matrix are assigned once for the whole
+// system, in one of three ways:
+//
+//   - if only x, y, z, w, v and u are used, they keep their classic
+//     columns up to the last letter used, so "y = 1" still has an x
+//     column but no z column;
+//   - if every name is one letter with a subscript, like x1, x2, x3, the
+//     columns follow the subscripts;
+//   - otherwise the columns are in order of first appearance, so
+//     "a + 2b = 3; b - c = 1" has the columns a, b, c.
+
+// parsedEquation is an equation with its terms collected by variable.
+type parsedEquation struct {
+	names    []string // variables in order of first appearance
+	coeffs   map[string]float64
+	constant float64 // the right-hand side once every term is moved over
+}
+
+// add adds coeff to the coefficient of name.
+func (p *parsedEquation) add(name string, coeff float64) {
+	if _, ok := p.coeffs[name]; !ok {
+		p.names = append(p.names, name)
+	}
+	p.coeffs[name] += coeff
+}
+
+// row writes the equation as a matrix row over the columns of vars: one
+// coefficient per column, then the constant.
+func (p *parsedEquation) row(vars []string) []float64 {
+	row := make([]float64, len(vars)+1)
+	for j, name := range vars {
+		row[j] = p.coeffs[name]
+	}
+	row[len(vars)] = p.constant
+	return row
+}
+
+// systemVariables assigns columns to the variables used by a system's
+// equations, see above, and returns the variable of each column.
+func systemVariables(equations []*parsedEquation) []string {
+	var names []string
+	for _, p := range equations {
+		for _, name := range p.names {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	switch {
+	case allClassic(names):
+		used := 0
+		for _, name := range names {
+			used = max(used, strings.Index(variableLetters, name)+1)
+		}
+		vars := make([]string, used)
+		for j := range vars {
+			vars[j] = VariableName(j)
+		}
+		return vars
+	case subscriptLetter(names) != 0:
+		slices.SortFunc(names, func(a, b string) int {
+			return subscript(a) - subscript(b)
+		})
+	}
+	return names
+}
+
+// allClassic reports whether every name is one of x, y, z, w, v and u.
+func allClassic(names []string) bool {
+	for _, name := range names {
+		if len(name) != 1 || !strings.Contains(variableLetters, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// subscriptLetter returns the letter shared by names that are all that
+// letter with a subscript, as in x1, x2, x3, or 0 if they aren't.
+func subscriptLetter(names []string) byte {
+	if len(names) == 0 {
+		return 0
+	}
+	letter := names[0][0]
+	for _, name := range names {
+		if name[0] != letter || subscript(name) < 0 {
+			return 0
+		}
+	}
+	return letter
+}
+
+// subscript returns the number after the letter of a name such as x12,
+// or -1 if there is none.
+func subscript(name string) int {
+	n, err := strconv.Atoi(name[1:])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// VariableName returns the name of column col: the one used in the
+// equations the matrix was parsed from, or the classic name.
+func (m *Matrix) VariableName(col int) string {
+	if col < len(m.names) {
+		return m.names[col]
+	}
+	return VariableName(col)
+}
+
+// VariableName returns the name of column col, as Matrix.VariableName.
+func (m *RatMatrix) VariableName(col int) string {
+	if col < len(m.names) {
+		return m.names[col]
+	}
+	return VariableName(col)
+}
@@ -0,0 +1,66 @@
+package solver
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSystemVariables(t *testing.T) {
+	tests := []struct {
+		equations []string
+		want      []string
+	}{
+		{[]string{"y = 1", "x + z = 2", "z = 3"}, []string{"x", "y", "z"}},
+		{[]string{"x + y = 1", "x = 2", "y = 3"}, []string{"x", "y"}},
+		{[]string{"0 = 0", "x = 1"}, []string{"x"}},
+		{[]string{"b + 2c = 3", "a - c = 1", "a = 2"}, []string{"b", "c", "a"}},
+		{[]string{"a + b = 3", "a - b = 1", "a = 2"}, []string{"a", "b"}},
+		{[]string{"x3 + x1 = 3", "x2 = 1", "x10 = 2"}, []string{"x1", "x2", "x3", "x10"}},
+		{[]string{"x1 + x2 = 3", "x2 = 1", "x1 = 2"}, []string{"x1", "x2"}},
+		{[]string{"x + a = 1", "x1 = 2"}, []string{"x", "a", "x1"}},
+	}
+	for _, tt := range tests {
+		_, vars, errs := ParseSystem(tt.equations)
+		if err := errors.Join(errs...); err != nil {
+			t.Fatalf("ParseSystem(%q): %v", tt.equations, err)
+		}
+		if !slices.Equal(vars, tt.want) {
+			t.Errorf("%q: variables %q, want %q", tt.equations, vars, tt.want)
+		}
+	}
+}
+
+func TestParseSystemWithNamedVariables(t *testing.T) {
+	coeffs, _, errs := ParseSystem([]string{"p + 2q = 5", "3q - p = 0"})
+	if err := errors.Join(errs...); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]float64{{1, 2, 5}, {-1, 3, 0}}
+	for i := range want {
+		if !slices.Equal(coeffs[i], want[i]) {
+			t.Errorf("row %d = %v, want %v", i+1, coeffs[i], want[i])
+		}
+	}
+}
+
+func TestOverdeterminedSystemIsUnique(t *testing.T) {
+	for _, equations := range [][]string{
+		{"a + b = 3", "a - b = 1", "a = 2"},
+		{"x1 + x2 = 3", "x2 = 1", "x1 = 2"},
+	} {
+		m, errs := Parse(equations)
+		if m == nil {
+			t.Fatalf("Parse(%q): %v", equations, errors.Join(errs...))
+		}
+		m.GaussianElimination()
+		if kind := m.Classify(); kind != Unique {
+			t.Errorf("%q: classified as %v, want unique", equations, kind)
+		}
+	}
+	m, _ := Parse([]string{"a + b = 3", "a - b = 1", "a = 2"})
+	m.GaussianElimination()
+	if got := m.SolutionString(); got != "a = 2, b = 1" {
+		t.Errorf("solution %q, want a = 2, b = 1", got)
+	}
+}